@@ -0,0 +1,60 @@
+// Copyright (c) 2014 Feng Wang <wffrank1987@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language
+
+package ann
+
+import (
+	"fmt"
+)
+
+const (
+	// The dimension of the vector differs from the index
+	errorDimensionMismatch = iota
+	// The id is already in the index
+	errorDuplicateId
+	// The id is not in the index
+	errorUnknownId
+	// The saved graph links nodes that do not exist
+	errorCorruptedModel
+)
+
+type error_ int
+
+func (e error_) Error() string {
+	switch e {
+	case errorDimensionMismatch:
+		return "Vector dimension does not match the index"
+	case errorDuplicateId:
+		return "Id is already indexed"
+	case errorUnknownId:
+		return "Id is not indexed"
+	case errorCorruptedModel:
+		return "The saved graph links to unknown nodes"
+	}
+	return fmt.Sprintf("Unknown error code %d", e)
+}
+
+func (e error_) String() string {
+	return e.Error()
+}
+
+var (
+	// The dimension of the vector differs from the index
+	ErrorDimensionMismatch error_ = error_(errorDimensionMismatch)
+	// The id is already in the index
+	ErrorDuplicateId error_ = error_(errorDuplicateId)
+	// The id is not in the index
+	ErrorUnknownId error_ = error_(errorUnknownId)
+	// The saved graph links nodes that do not exist
+	ErrorCorruptedModel error_ = error_(errorCorruptedModel)
+)
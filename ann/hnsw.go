@@ -0,0 +1,536 @@
+// Copyright (c) 2014 Feng Wang <wffrank1987@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language
+
+package ann
+
+import (
+	"container/heap"
+	"encoding/gob"
+	"io"
+	gomath "math"
+	"math/rand"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/numb3r3/gorec/math"
+)
+
+// The options of the HNSW index
+type HNSWOptions struct {
+	Metric Metric
+
+	// The number of links of a node on the upper layers, twice on layer 0
+	M int
+
+	// The size of the dynamic candidate list when inserting
+	EfConstruction int
+
+	// The default size of the dynamic candidate list when searching
+	EfSearch int
+
+	// The seed of the random levels
+	Seed int64
+}
+
+func DefaultHNSWOptions() HNSWOptions {
+	return HNSWOptions{
+		Metric:         InnerProduct,
+		M:              16,
+		EfConstruction: 200,
+		EfSearch:       50,
+		Seed:           1,
+	}
+}
+
+// A search result of the index
+type Neighbor struct {
+	Id int
+
+	Distance float64
+}
+
+type node struct {
+	id int
+
+	vector []float64
+
+	// layer -> the indexes of the linked nodes
+	links [][]int
+
+	deleted bool
+}
+
+// The hierarchical navigable small world graph (Malkov and Yashunin, 2016)
+// for approximate nearest neighbor search. The index is safe for concurrent
+// use; searches run in parallel while an insert or delete is exclusive.
+// Deleted nodes are only marked, they keep routing the searches but are
+// never returned.
+type HNSW struct {
+	options HNSWOptions
+
+	dim int
+
+	nodes []*node
+
+	// id -> the index of its node
+	ids map[int]int
+
+	entry int
+
+	maxLevel int
+
+	numDeleted int
+
+	// 1 / ln(M)
+	levelMultiplier float64
+
+	rng *rand.Rand
+
+	mutex sync.RWMutex
+}
+
+// Create an empty index of the vectors of the dimension
+func NewHNSW(dim int, options HNSWOptions) *HNSW {
+	index := new(HNSW)
+	index.options = options
+	index.dim = dim
+	index.ids = make(map[int]int)
+	index.entry = -1
+	if options.M < 2 {
+		index.options.M = 2
+	}
+	index.levelMultiplier = 1 / gomath.Log(float64(index.options.M))
+	index.rng = rand.New(rand.NewSource(options.Seed))
+	return index
+}
+
+// Index the rows of the matrix, the id of a row is its row number
+func BuildHNSW(M *math.DenseMatrix, options HNSWOptions) *HNSW {
+	index := NewHNSW(M.Cols(), options)
+	for i := 0; i < M.Rows(); i++ {
+		index.Insert(i, M.RowSlice(i))
+	}
+	return index
+}
+
+// Get the dimension of the indexed vectors
+func (index *HNSW) Dim() int {
+	return index.dim
+}
+
+// Get the number of the indexed vectors not deleted
+func (index *HNSW) Len() int {
+	index.mutex.RLock()
+	defer index.mutex.RUnlock()
+	return len(index.ids)
+}
+
+// Whether the id is indexed and not deleted
+func (index *HNSW) Contains(id int) bool {
+	index.mutex.RLock()
+	defer index.mutex.RUnlock()
+	_, ok := index.ids[id]
+	return ok
+}
+
+// Set the default size of the candidate list when searching
+func (index *HNSW) SetEfSearch(ef int) {
+	index.mutex.Lock()
+	defer index.mutex.Unlock()
+	index.options.EfSearch = ef
+}
+
+func (index *HNSW) maxLinks(level int) int {
+	if level == 0 {
+		return 2 * index.options.M
+	}
+	return index.options.M
+}
+
+func (index *HNSW) randomLevel() int {
+	return int(-gomath.Log(1-index.rng.Float64()) * index.levelMultiplier)
+}
+
+func (index *HNSW) distance(q []float64, i int) float64 {
+	return index.options.Metric.distance(q, index.nodes[i].vector)
+}
+
+// Add the vector with the id to the index
+func (index *HNSW) Insert(id int, vector []float64) error {
+	if len(vector) != index.dim {
+		return ErrorDimensionMismatch
+	}
+	index.mutex.Lock()
+	defer index.mutex.Unlock()
+	if _, ok := index.ids[id]; ok {
+		return ErrorDuplicateId
+	}
+
+	level := index.randomLevel()
+	q := index.options.Metric.prepare(vector)
+	n := &node{id: id, vector: q, links: make([][]int, level+1)}
+	current := len(index.nodes)
+	index.nodes = append(index.nodes, n)
+	index.ids[id] = current
+
+	if index.entry < 0 {
+		index.entry, index.maxLevel = current, level
+		return nil
+	}
+
+	entry := index.entry
+	for l := index.maxLevel; l > level; l-- {
+		entry = index.greedy(q, entry, l)
+	}
+	entries := []int{entry}
+	for l := minInt(level, index.maxLevel); l >= 0; l-- {
+		candidates := index.searchLayer(q, entries, index.options.EfConstruction, l)
+		n.links[l] = index.selectNeighbors(q, candidates, index.options.M)
+		for _, neighbor := range n.links[l] {
+			index.link(neighbor, current, l)
+		}
+		entries = entries[:0]
+		for _, c := range candidates {
+			entries = append(entries, c.index)
+		}
+	}
+	if level > index.maxLevel {
+		index.entry, index.maxLevel = current, level
+	}
+	return nil
+}
+
+// Add the link from node i to node j on the layer, pruning the links of i
+// when there are too many
+func (index *HNSW) link(i, j, level int) {
+	n := index.nodes[i]
+	n.links[level] = append(n.links[level], j)
+	if len(n.links[level]) <= index.maxLinks(level) {
+		return
+	}
+	candidates := make([]candidate, len(n.links[level]))
+	for k, linked := range n.links[level] {
+		candidates[k] = candidate{linked, index.distance(n.vector, linked)}
+	}
+	sort.Sort(byDistance(candidates))
+	n.links[level] = index.selectNeighbors(n.vector, candidates, index.maxLinks(level))
+}
+
+// Select up to m neighbors from the candidates sorted by ascending distance
+// by the heuristic keeping the candidates nearer to q than to any selected
+// one, and filling up with the nearest of the rest
+func (index *HNSW) selectNeighbors(q []float64, candidates []candidate, m int) []int {
+	selected := make([]int, 0, m)
+	var pruned []int
+	for _, c := range candidates {
+		if len(selected) >= m {
+			break
+		}
+		diverse := true
+		for _, s := range selected {
+			if index.options.Metric.distance(index.nodes[c.index].vector, index.nodes[s].vector) < c.distance {
+				diverse = false
+				break
+			}
+		}
+		if diverse {
+			selected = append(selected, c.index)
+		} else {
+			pruned = append(pruned, c.index)
+		}
+	}
+	for _, p := range pruned {
+		if len(selected) >= m {
+			break
+		}
+		selected = append(selected, p)
+	}
+	return selected
+}
+
+// Walk greedily to the node nearest to q on the layer
+func (index *HNSW) greedy(q []float64, entry, level int) int {
+	best, bestDistance := entry, index.distance(q, entry)
+	for changed := true; changed; {
+		changed = false
+		for _, neighbor := range index.nodes[best].links[level] {
+			if d := index.distance(q, neighbor); d < bestDistance {
+				best, bestDistance, changed = neighbor, d, true
+			}
+		}
+	}
+	return best
+}
+
+// Get the ef nearest nodes to q found on the layer from the entries,
+// sorted by ascending distance
+func (index *HNSW) searchLayer(q []float64, entries []int, ef, level int) []candidate {
+	visited := make(map[int]bool)
+	near := &minHeap{}
+	far := &maxHeap{}
+	for _, e := range entries {
+		visited[e] = true
+		c := candidate{e, index.distance(q, e)}
+		heap.Push(near, c)
+		heap.Push(far, c)
+	}
+	for near.Len() > 0 {
+		c := heap.Pop(near).(candidate)
+		if far.Len() >= ef && c.distance > (*far)[0].distance {
+			break
+		}
+		for _, neighbor := range index.nodes[c.index].links[level] {
+			if visited[neighbor] {
+				continue
+			}
+			visited[neighbor] = true
+			d := index.distance(q, neighbor)
+			if far.Len() < ef || d < (*far)[0].distance {
+				heap.Push(near, candidate{neighbor, d})
+				heap.Push(far, candidate{neighbor, d})
+				if far.Len() > ef {
+					heap.Pop(far)
+				}
+			}
+		}
+	}
+	result := make([]candidate, far.Len())
+	for i := len(result) - 1; i >= 0; i-- {
+		result[i] = heap.Pop(far).(candidate)
+	}
+	return result
+}
+
+// Get the approximate k nearest neighbors of the query with the default ef
+func (index *HNSW) Search(query []float64, k int) []*Neighbor {
+	index.mutex.RLock()
+	ef := index.options.EfSearch
+	index.mutex.RUnlock()
+	return index.SearchEf(query, k, ef)
+}
+
+// Get the approximate k nearest neighbors of the query, sorted by ascending
+// distance. A larger ef trades speed for recall.
+func (index *HNSW) SearchEf(query []float64, k, ef int) []*Neighbor {
+	if len(query) != index.dim || k <= 0 {
+		return nil
+	}
+	index.mutex.RLock()
+	defer index.mutex.RUnlock()
+	if index.entry < 0 {
+		return nil
+	}
+	if ef < k {
+		ef = k
+	}
+	// widen the search over the deleted nodes
+	if index.numDeleted > 0 && len(index.ids) > 0 {
+		ef += ef * index.numDeleted / len(index.ids)
+	}
+
+	q := index.options.Metric.prepare(query)
+	entry := index.entry
+	for l := index.maxLevel; l > 0; l-- {
+		entry = index.greedy(q, entry, l)
+	}
+	neighbors := make([]*Neighbor, 0, k)
+	for _, c := range index.searchLayer(q, []int{entry}, ef, 0) {
+		if len(neighbors) >= k {
+			break
+		}
+		if n := index.nodes[c.index]; !n.deleted {
+			neighbors = append(neighbors, &Neighbor{Id: n.id, Distance: c.distance})
+		}
+	}
+	return neighbors
+}
+
+// Get the indexed vector of the id, normalized for the cosine metric
+func (index *HNSW) Vector(id int) ([]float64, error) {
+	index.mutex.RLock()
+	defer index.mutex.RUnlock()
+	i, ok := index.ids[id]
+	if !ok {
+		return nil, ErrorUnknownId
+	}
+	return index.nodes[i].vector, nil
+}
+
+// Remove the id from the search results
+func (index *HNSW) Delete(id int) error {
+	index.mutex.Lock()
+	defer index.mutex.Unlock()
+	i, ok := index.ids[id]
+	if !ok {
+		return ErrorUnknownId
+	}
+	index.nodes[i].deleted = true
+	delete(index.ids, id)
+	index.numDeleted++
+	return nil
+}
+
+type hnswNode struct {
+	Id      int
+	Vector  []float64
+	Links   [][]int
+	Deleted bool
+}
+
+type hnswSnapshot struct {
+	Options  HNSWOptions
+	Dim      int
+	Entry    int
+	MaxLevel int
+	Nodes    []hnswNode
+}
+
+// Write the index to w
+func (index *HNSW) Save(w io.Writer) error {
+	index.mutex.RLock()
+	defer index.mutex.RUnlock()
+	s := hnswSnapshot{index.options, index.dim, index.entry, index.maxLevel, make([]hnswNode, len(index.nodes))}
+	for i, n := range index.nodes {
+		s.Nodes[i] = hnswNode{n.id, n.vector, n.links, n.deleted}
+	}
+	return gob.NewEncoder(w).Encode(&s)
+}
+
+// Write the index to the file
+func (index *HNSW) SaveFile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := index.Save(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Read the index written by Save, ErrorCorruptedModel is returned if the
+// entry or a link is not a saved node. The levels of the vectors inserted
+// afterwards are drawn from a seed derived from Seed and the number of the
+// nodes, so they do not repeat the draws of the saved ones.
+func LoadHNSW(r io.Reader) (*HNSW, error) {
+	var s hnswSnapshot
+	if err := gob.NewDecoder(r).Decode(&s); err != nil {
+		return nil, err
+	}
+	if !s.valid() {
+		return nil, ErrorCorruptedModel
+	}
+	index := NewHNSW(s.Dim, s.Options)
+	index.rng = rand.New(rand.NewSource(s.Options.Seed + int64(len(s.Nodes))))
+	index.entry, index.maxLevel = s.Entry, s.MaxLevel
+	index.nodes = make([]*node, len(s.Nodes))
+	for i, n := range s.Nodes {
+		index.nodes[i] = &node{n.Id, n.Vector, n.Links, n.Deleted}
+		if n.Deleted {
+			index.numDeleted++
+		} else {
+			index.ids[n.Id] = i
+		}
+	}
+	return index, nil
+}
+
+// Whether the entry and the links are the indexes of the nodes
+func (s *hnswSnapshot) valid() bool {
+	if len(s.Nodes) == 0 {
+		return s.Entry == -1
+	}
+	if s.Entry < 0 || s.Entry >= len(s.Nodes) || len(s.Nodes[s.Entry].Links) != s.MaxLevel+1 {
+		return false
+	}
+	for _, n := range s.Nodes {
+		if len(n.Vector) != s.Dim {
+			return false
+		}
+		for _, links := range n.Links {
+			for _, j := range links {
+				if j < 0 || j >= len(s.Nodes) {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
+
+// Read the index written by SaveFile
+func LoadHNSWFile(path string) (*HNSW, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return LoadHNSW(f)
+}
+
+type candidate struct {
+	index int
+
+	distance float64
+}
+
+type byDistance []candidate
+
+func (s byDistance) Len() int { return len(s) }
+
+func (s byDistance) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+
+func (s byDistance) Less(i, j int) bool { return s[i].distance < s[j].distance }
+
+type minHeap []candidate
+
+func (h minHeap) Len() int { return len(h) }
+
+func (h minHeap) Less(i, j int) bool { return h[i].distance < h[j].distance }
+
+func (h minHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *minHeap) Push(x interface{}) { *h = append(*h, x.(candidate)) }
+
+func (h *minHeap) Pop() interface{} {
+	old := *h
+	c := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return c
+}
+
+type maxHeap []candidate
+
+func (h maxHeap) Len() int { return len(h) }
+
+func (h maxHeap) Less(i, j int) bool { return h[i].distance > h[j].distance }
+
+func (h maxHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *maxHeap) Push(x interface{}) { *h = append(*h, x.(candidate)) }
+
+func (h *maxHeap) Pop() interface{} {
+	old := *h
+	c := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return c
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
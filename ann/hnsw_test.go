@@ -0,0 +1,169 @@
+// Copyright (c) 2014 Feng Wang <wffrank1987@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language
+
+package ann
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"github.com/numb3r3/gorec/math"
+	"github.com/numb3r3/gorec/utils"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+func randomMatrix(rows, cols int, seed int64) *math.DenseMatrix {
+	rng := rand.New(rand.NewSource(seed))
+	M := math.Zeros(rows, cols)
+	for i := 0; i < rows; i++ {
+		row := M.RowSlice(i)
+		for j := range row {
+			row[j] = rng.NormFloat64()
+		}
+	}
+	return M
+}
+
+// Get the ids of the exact k nearest rows
+func bruteForce(M *math.DenseMatrix, metric Metric, q []float64, k int) map[int]bool {
+	prepared := metric.prepare(q)
+	ids := make([]int, M.Rows())
+	distances := make([]float64, M.Rows())
+	for i := range ids {
+		ids[i] = i
+		distances[i] = metric.distance(prepared, metric.prepare(M.RowSlice(i)))
+	}
+	sort.Slice(ids, func(a, b int) bool { return distances[ids[a]] < distances[ids[b]] })
+	exact := make(map[int]bool)
+	for _, id := range ids[:k] {
+		exact[id] = true
+	}
+	return exact
+}
+
+func ids(neighbors []*Neighbor) string {
+	s := ""
+	for _, n := range neighbors {
+		s += fmt.Sprint(n.Id, " ")
+	}
+	return s
+}
+
+func TestHNSWRecall(t *testing.T) {
+	M := randomMatrix(2000, 16, 1)
+	queries := randomMatrix(50, 16, 2)
+	for _, metric := range []Metric{InnerProduct, Cosine, L2} {
+		options := DefaultHNSWOptions()
+		options.Metric = metric
+		index := BuildHNSW(M, options)
+		hits := 0
+		for i := 0; i < queries.Rows(); i++ {
+			q := queries.RowSlice(i)
+			exact := bruteForce(M, metric, q, 10)
+			for _, n := range index.Search(q, 10) {
+				if exact[n.Id] {
+					hits++
+				}
+			}
+		}
+		if recall := float64(hits) / 500; recall < 0.9 {
+			t.Errorf("recall of %v is %v", metric, recall)
+		}
+	}
+}
+
+func TestHNSWDeleteAndSave(t *testing.T) {
+	M := randomMatrix(200, 8, 3)
+	options := DefaultHNSWOptions()
+	options.Metric = L2
+	index := BuildHNSW(M, options)
+	q := M.RowSlice(7)
+	utils.Expect(t, "7 ", ids(index.Search(q, 1)))
+	utils.Expect(t, ErrorDuplicateId.Error(), index.Insert(7, q).Error())
+
+	index.Delete(7)
+	utils.Expect(t, "false", fmt.Sprint(index.Contains(7)))
+	for _, n := range index.Search(q, 10) {
+		if n.Id == 7 {
+			t.Error("deleted id is returned")
+		}
+	}
+
+	var buffer bytes.Buffer
+	if err := index.Save(&buffer); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadHNSW(&buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	utils.Expect(t, "199", fmt.Sprint(loaded.Len()))
+	utils.Expect(t, ids(index.Search(q, 5)), ids(loaded.Search(q, 5)))
+
+	// the levels after the load do not repeat the first draws
+	utils.Expect(t, "false", fmt.Sprint(NewHNSW(8, options).rng.Float64() == loaded.rng.Float64()))
+
+	// the deleted id can be inserted again
+	for _, index := range []*HNSW{index, loaded} {
+		if err := index.Insert(7, q); err != nil {
+			t.Fatal(err)
+		}
+		utils.Expect(t, "true", fmt.Sprint(index.Contains(7)))
+		utils.Expect(t, "7 ", ids(index.Search(q, 1)))
+	}
+}
+
+func TestHNSWSaveFile(t *testing.T) {
+	M := randomMatrix(100, 8, 4)
+	index := BuildHNSW(M, DefaultHNSWOptions())
+	dir, err := ioutil.TempDir("", "hnsw")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "index.gob")
+	if err := index.SaveFile(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadHNSWFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := M.RowSlice(3)
+	utils.Expect(t, ids(index.Search(q, 5)), ids(loaded.Search(q, 5)))
+
+	_, err = LoadHNSWFile(filepath.Join(dir, "missing.gob"))
+	utils.Expect(t, "true", fmt.Sprint(err != nil))
+}
+
+func TestHNSWLoadCorrupted(t *testing.T) {
+	nodes := []hnswNode{{Id: 0, Vector: []float64{1}, Links: [][]int{{1}}}, {Id: 1, Vector: []float64{2}, Links: [][]int{{0}}}}
+	for _, s := range []hnswSnapshot{
+		{DefaultHNSWOptions(), 1, 2, 0, nodes},
+		{DefaultHNSWOptions(), 1, 0, 1, nodes},
+		{DefaultHNSWOptions(), 1, 0, 0, []hnswNode{nodes[0]}},
+		{DefaultHNSWOptions(), 1, 0, 0, nil},
+	} {
+		var buffer bytes.Buffer
+		if err := gob.NewEncoder(&buffer).Encode(&s); err != nil {
+			t.Fatal(err)
+		}
+		_, err := LoadHNSW(&buffer)
+		utils.Expect(t, ErrorCorruptedModel.Error(), err)
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright (c) 2014 Feng Wang <wffrank1987@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language
+
+package ann
+
+import (
+	gomath "math"
+
+	"github.com/numb3r3/gorec/math"
+)
+
+// The metrics of the index, the smaller distance the nearer
+type Metric int
+
+const (
+	// The negative inner product, for maximum inner product search
+	InnerProduct Metric = iota
+
+	// One minus the cosine similarity, the vectors are normalized when indexed
+	Cosine
+
+	// The squared euclidean distance
+	L2
+)
+
+func (metric Metric) String() string {
+	switch metric {
+	case InnerProduct:
+		return "inner product"
+	case Cosine:
+		return "cosine"
+	case L2:
+		return "l2"
+	}
+	return "unknown"
+}
+
+func (metric Metric) distance(a, b []float64) float64 {
+	switch metric {
+	case L2:
+		var d float64
+		for i, x := range a {
+			d += (x - b[i]) * (x - b[i])
+		}
+		return d
+	case Cosine:
+		return 1 - math.DotSlices(a, b)
+	}
+	return -math.DotSlices(a, b)
+}
+
+// Get the vector to index, a normalized copy for the cosine metric
+func (metric Metric) prepare(v []float64) []float64 {
+	prepared := make([]float64, len(v))
+	copy(prepared, v)
+	if metric == Cosine {
+		if norm := gomath.Sqrt(math.DotSlices(v, v)); norm > 0 {
+			for i := range prepared {
+				prepared[i] /= norm
+			}
+		}
+	}
+	return prepared
+}
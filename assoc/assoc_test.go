@@ -0,0 +1,118 @@
+// Copyright (c) 2014 Feng Wang <wffrank1987@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language
+
+package assoc
+
+import (
+	"fmt"
+	"github.com/numb3r3/gorec/core"
+	"github.com/numb3r3/gorec/utils"
+	"testing"
+	"time"
+)
+
+func groceries() []Basket {
+	// 0: bread, 1: milk, 2: diapers, 3: beer, 4: eggs, 5: cola
+	return []Basket{
+		NewBasket(0, 1),
+		NewBasket(0, 2, 3, 4),
+		NewBasket(1, 2, 3, 5),
+		NewBasket(0, 1, 2, 3),
+		NewBasket(0, 1, 2, 5),
+	}
+}
+
+func itemsetsString(itemsets []*Itemset) string {
+	s := ""
+	for _, set := range itemsets {
+		s += fmt.Sprint(set.Items, set.Count, " ")
+	}
+	return s
+}
+
+func TestFPGrowth(t *testing.T) {
+	options := MiningOptions{MinSupport: 0.6}
+	expect := "[0] 4 [1] 4 [2] 4 [3] 3 [0 1] 3 [0 2] 3 [1 2] 3 [2 3] 3 "
+	utils.Expect(t, expect, itemsetsString(Apriori(groceries(), options)))
+	utils.Expect(t, expect, itemsetsString(FPGrowth(groceries(), options)))
+
+	options = MiningOptions{MinSupport: 0.2, MaxLength: 3}
+	utils.Expect(t, itemsetsString(Apriori(groceries(), options)), itemsetsString(FPGrowth(groceries(), options)))
+}
+
+func TestRules(t *testing.T) {
+	itemsets := FPGrowth(groceries(), MiningOptions{MinSupport: 0.6})
+	rules := GenerateRules(itemsets, 5, 0.7)
+	// beer => diapers always holds
+	utils.Expect(t, "[3] [2] 1", fmt.Sprint(rules[0].Antecedent, rules[0].Consequent, rules[0].Confidence))
+	utils.ExpectNear(t, 1.25, rules[0].Lift, 1e-9)
+
+	m := NewRuleRecommender(DefaultRuleOptions())
+	m.rules = rules
+	top := m.RecommendForBasket(NewBasket(3, 4), 1)
+	utils.Expect(t, "2", top[0].ProductId)
+}
+
+func TestBasketsFromRecords(t *testing.T) {
+	t0 := time.Date(2014, 1, 1, 0, 0, 0, 0, time.UTC)
+	records := []*core.Record{
+		core.NewEvent(1, 2, core.EventPurchase, t0.Add(2*time.Hour)),
+		core.NewEvent(1, 0, core.EventPurchase, t0),
+		core.NewEvent(1, 3, core.EventView, t0.Add(2*time.Hour+time.Minute)),
+		core.NewEvent(1, 1, core.EventPurchase, t0.Add(10*time.Minute)),
+		core.NewEvent(0, 4, core.EventClick, t0),
+	}
+
+	// the gap of over 30 minutes starts a new session of user 1
+	baskets, owners := BasketsFromRecords(records, DefaultBasketOptions())
+	utils.Expect(t, "[[4] [0 1] [2 3]] [0 1 1]", fmt.Sprint(baskets, " ", owners))
+
+	options := DefaultBasketOptions()
+	options.SessionGap = 0
+	baskets, owners = BasketsFromRecords(records, options)
+	utils.Expect(t, "[[4] [0 1 2 3]] [0 1]", fmt.Sprint(baskets, " ", owners))
+
+	// user 0 has no purchase and no basket
+	options = DefaultBasketOptions()
+	options.Events = map[core.EventType]bool{core.EventPurchase: true}
+	baskets, owners = BasketsFromRecords(records, options)
+	utils.Expect(t, "[[0 1] [2]] [1 1]", fmt.Sprint(baskets, " ", owners))
+}
+
+func TestRuleRecommender(t *testing.T) {
+	var records []*core.Record
+	for u, basket := range groceries() {
+		for _, item := range basket {
+			records = append(records, core.NewEvent(u, item, core.EventPurchase, time.Time{}))
+		}
+	}
+	options := DefaultRuleOptions()
+	options.Mining.MinSupport = 0.6
+	options.MinConfidence = 0.7
+	m := NewRuleRecommender(options)
+	if err := m.Fit(records); err != nil {
+		t.Fatal(err)
+	}
+
+	// bread => milk and diapers => milk for the basket of user 1
+	top := m.Recommend(1, nil, 3)
+	utils.Expect(t, "1", len(top))
+	utils.Expect(t, "1", top[0].ProductId)
+	utils.ExpectNear(t, 0.75, top[0].Score, 1e-9)
+
+	top = m.Recommend(0, nil, 3)
+	utils.Expect(t, "1", len(top))
+	utils.Expect(t, "2", top[0].ProductId)
+	utils.Expect(t, "0", len(m.Recommend(5, nil, 3)))
+	utils.Expect(t, core.ErrorEmptyRecords.Error(), m.Fit(nil))
+}
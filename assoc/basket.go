@@ -0,0 +1,114 @@
+// Copyright (c) 2014 Feng Wang <wffrank1987@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language
+
+package assoc
+
+import (
+	"sort"
+	"time"
+
+	"github.com/numb3r3/gorec/core"
+)
+
+// A set of products bought or viewed together, ordered by id
+type Basket []int
+
+// Make a basket of the products, removing the duplicates
+func NewBasket(products ...int) Basket {
+	b := append(Basket(nil), products...)
+	sort.Ints(b)
+	return b[:unique(b)]
+}
+
+// Whether the basket contains the product
+func (b Basket) Contains(product int) bool {
+	i := sort.SearchInts(b, product)
+	return i < len(b) && b[i] == product
+}
+
+// Whether all of the items are in the basket, both ordered by id
+func (b Basket) ContainsAll(items []int) bool {
+	i := 0
+	for _, item := range items {
+		for i < len(b) && b[i] < item {
+			i++
+		}
+		if i == len(b) || b[i] != item {
+			return false
+		}
+	}
+	return true
+}
+
+// Remove the adjacent duplicates of the sorted ids, return the new length
+func unique(ids []int) int {
+	n := 0
+	for i, id := range ids {
+		if i == 0 || id != ids[n-1] {
+			ids[n] = id
+			n++
+		}
+	}
+	return n
+}
+
+// The options deriving the baskets from the records
+type BasketOptions struct {
+
+	// The records of a user are split into sessions when the gap between
+	// two consecutive records exceeds SessionGap. All of the records of a
+	// user form one basket if it is zero.
+	SessionGap time.Duration
+
+	// Only the records of the events are used, all events if nil
+	Events map[core.EventType]bool
+}
+
+func DefaultBasketOptions() BasketOptions {
+	return BasketOptions{SessionGap: 30 * time.Minute}
+}
+
+// Derive the baskets (sessions) from the records, along with the owner
+// user id of each basket. The baskets of each user are in time order,
+// and the users are in ascending id order.
+func BasketsFromRecords(records []*core.Record, options BasketOptions) (baskets []Basket, owners []int) {
+	byUser := make(map[int][]*core.Record)
+	for _, r := range records {
+		if options.Events != nil && !options.Events[r.Event] {
+			continue
+		}
+		byUser[r.UserId] = append(byUser[r.UserId], r)
+	}
+	users := make([]int, 0, len(byUser))
+	for u := range byUser {
+		users = append(users, u)
+	}
+	sort.Ints(users)
+
+	for _, u := range users {
+		history := byUser[u]
+		core.SortRecordsByTime(history)
+		var current []int
+		for i, r := range history {
+			if i > 0 && options.SessionGap > 0 && r.Timestamp.Sub(history[i-1].Timestamp) > options.SessionGap {
+				baskets = append(baskets, NewBasket(current...))
+				owners = append(owners, u)
+				current = nil
+			}
+			current = append(current, r.ProductId)
+		}
+		baskets = append(baskets, NewBasket(current...))
+		owners = append(owners, u)
+	}
+	return baskets, owners
+}
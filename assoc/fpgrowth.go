@@ -0,0 +1,145 @@
+// Copyright (c) 2014 Feng Wang <wffrank1987@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language
+
+package assoc
+
+import (
+	"sort"
+)
+
+// A node of the FP-tree
+type fpNode struct {
+	item  int
+	count int
+
+	parent   *fpNode
+	children map[int]*fpNode
+
+	// The next node of the same item
+	next *fpNode
+}
+
+// The frequent-pattern tree of the weighted transactions
+type fpTree struct {
+	root *fpNode
+
+	// item -> the first node of the item
+	headers map[int]*fpNode
+
+	// item -> the total count of the item
+	counts map[int]int
+}
+
+// Build the FP-tree of the transactions with the weights, keeping only
+// the items with total count at least threshold
+func buildFPTree(transactions [][]int, weights []int, threshold int) *fpTree {
+	counts := make(map[int]int)
+	for t, items := range transactions {
+		for _, item := range items {
+			counts[item] += weights[t]
+		}
+	}
+	for item, c := range counts {
+		if c < threshold {
+			delete(counts, item)
+		}
+	}
+
+	tree := &fpTree{
+		root:    &fpNode{children: make(map[int]*fpNode)},
+		headers: make(map[int]*fpNode),
+		counts:  counts,
+	}
+	path := make([]int, 0)
+	for t, items := range transactions {
+		path = path[:0]
+		for _, item := range items {
+			if _, ok := counts[item]; ok {
+				path = append(path, item)
+			}
+		}
+		// the more frequent items are closer to the root
+		sort.Slice(path, func(a, b int) bool {
+			ca, cb := counts[path[a]], counts[path[b]]
+			if ca != cb {
+				return ca > cb
+			}
+			return path[a] < path[b]
+		})
+		tree.insert(path, weights[t])
+	}
+	return tree
+}
+
+func (tree *fpTree) insert(path []int, weight int) {
+	node := tree.root
+	for _, item := range path {
+		child, ok := node.children[item]
+		if !ok {
+			child = &fpNode{item: item, parent: node, children: make(map[int]*fpNode)}
+			child.next = tree.headers[item]
+			tree.headers[item] = child
+			node.children[item] = child
+		}
+		child.count += weight
+		node = child
+	}
+}
+
+// Mine the frequent itemsets by FP-Growth (Han, Pei and Yin, 2000),
+// which needs no candidate generation and only two scans of the baskets
+func FPGrowth(baskets []Basket, options MiningOptions) []*Itemset {
+	threshold := minCount(len(baskets), options.MinSupport)
+	transactions := make([][]int, len(baskets))
+	weights := make([]int, len(baskets))
+	for i, b := range baskets {
+		transactions[i] = b
+		weights[i] = 1
+	}
+	var result []*Itemset
+	mineFPTree(buildFPTree(transactions, weights, threshold), nil, threshold, options.MaxLength, &result)
+	sortItemsets(result)
+	return result
+}
+
+func mineFPTree(tree *fpTree, suffix []int, threshold, maxLength int, result *[]*Itemset) {
+	for item, count := range tree.counts {
+		items := append(append([]int(nil), suffix...), item)
+		sort.Ints(items)
+		*result = append(*result, &Itemset{items, count})
+		if maxLength > 0 && len(items) >= maxLength {
+			continue
+		}
+
+		// the conditional pattern base of the item
+		var paths [][]int
+		var weights []int
+		for node := tree.headers[item]; node != nil; node = node.next {
+			var path []int
+			for p := node.parent; p != tree.root; p = p.parent {
+				path = append(path, p.item)
+			}
+			if len(path) > 0 {
+				paths = append(paths, path)
+				weights = append(weights, node.count)
+			}
+		}
+		if len(paths) == 0 {
+			continue
+		}
+		conditional := buildFPTree(paths, weights, threshold)
+		if len(conditional.counts) > 0 {
+			mineFPTree(conditional, append(append([]int(nil), suffix...), item), threshold, maxLength, result)
+		}
+	}
+}
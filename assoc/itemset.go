@@ -0,0 +1,153 @@
+// Copyright (c) 2014 Feng Wang <wffrank1987@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language
+
+package assoc
+
+import (
+	gomath "math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// A frequent itemset and the number of the baskets containing it
+type Itemset struct {
+	// The items ordered by id
+	Items []int
+
+	Count int
+}
+
+// The options of the frequent itemset mining
+type MiningOptions struct {
+
+	// The minimum fraction of the baskets containing an itemset
+	MinSupport float64
+
+	// The maximum size of the itemsets, unlimited if not positive
+	MaxLength int
+}
+
+func DefaultMiningOptions() MiningOptions {
+	return MiningOptions{MinSupport: 0.01, MaxLength: 3}
+}
+
+// The minimum number of the baskets containing a frequent itemset
+func minCount(numBaskets int, minSupport float64) int {
+	c := int(gomath.Ceil(minSupport * float64(numBaskets)))
+	if c < 1 {
+		c = 1
+	}
+	return c
+}
+
+// The key of the itemset in a map
+func itemsetKey(items []int) string {
+	s := make([]string, len(items))
+	for i, item := range items {
+		s[i] = strconv.Itoa(item)
+	}
+	return strings.Join(s, ",")
+}
+
+// Sort the itemsets by size and then by items
+func sortItemsets(itemsets []*Itemset) {
+	sort.Slice(itemsets, func(a, b int) bool {
+		x, y := itemsets[a].Items, itemsets[b].Items
+		if len(x) != len(y) {
+			return len(x) < len(y)
+		}
+		for i := range x {
+			if x[i] != y[i] {
+				return x[i] < y[i]
+			}
+		}
+		return false
+	})
+}
+
+// Mine the frequent itemsets by the level-wise Apriori algorithm
+// (Agrawal and Srikant, 1994). It is kept as the reference of FPGrowth.
+func Apriori(baskets []Basket, options MiningOptions) []*Itemset {
+	threshold := minCount(len(baskets), options.MinSupport)
+	var result []*Itemset
+
+	counts := make(map[int]int)
+	for _, b := range baskets {
+		for _, item := range b {
+			counts[item]++
+		}
+	}
+	var level [][]int
+	for item, c := range counts {
+		if c >= threshold {
+			level = append(level, []int{item})
+			result = append(result, &Itemset{[]int{item}, c})
+		}
+	}
+	sortItemsets(result)
+	sort.Slice(level, func(a, b int) bool { return level[a][0] < level[b][0] })
+
+	for k := 2; len(level) > 0 && (options.MaxLength <= 0 || k <= options.MaxLength); k++ {
+		frequent := make(map[string]bool, len(level))
+		for _, items := range level {
+			frequent[itemsetKey(items)] = true
+		}
+		// join the itemsets sharing the first k-2 items, and prune the
+		// candidates with an infrequent subset
+		var candidates [][]int
+		for a := 0; a < len(level); a++ {
+			for b := a + 1; b < len(level); b++ {
+				x, y := level[a], level[b]
+				if itemsetKey(x[:k-2]) != itemsetKey(y[:k-2]) {
+					break
+				}
+				c := append(append([]int(nil), x...), y[k-2])
+				if allSubsetsFrequent(c, frequent) {
+					candidates = append(candidates, c)
+				}
+			}
+		}
+		level = level[:0:0]
+		for _, c := range candidates {
+			count := 0
+			for _, b := range baskets {
+				if b.ContainsAll(c) {
+					count++
+				}
+			}
+			if count >= threshold {
+				level = append(level, c)
+				result = append(result, &Itemset{c, count})
+			}
+		}
+	}
+	sortItemsets(result)
+	return result
+}
+
+func allSubsetsFrequent(items []int, frequent map[string]bool) bool {
+	subset := make([]int, 0, len(items)-1)
+	for skip := range items {
+		subset = subset[:0]
+		for i, item := range items {
+			if i != skip {
+				subset = append(subset, item)
+			}
+		}
+		if !frequent[itemsetKey(subset)] {
+			return false
+		}
+	}
+	return true
+}
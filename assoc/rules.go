@@ -0,0 +1,200 @@
+// Copyright (c) 2014 Feng Wang <wffrank1987@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language
+
+package assoc
+
+import (
+	"sort"
+
+	"github.com/numb3r3/gorec/core"
+)
+
+// An association rule Antecedent => Consequent
+type Rule struct {
+	// The items ordered by id
+	Antecedent, Consequent []int
+
+	// The fraction of the baskets containing both sides
+	Support float64
+
+	// The fraction of the baskets containing the antecedent which
+	// also contain the consequent
+	Confidence float64
+
+	// The confidence over the support of the consequent
+	Lift float64
+}
+
+// Generate the rules with confidence at least minConfidence from the
+// frequent itemsets mined from numBaskets baskets
+func GenerateRules(itemsets []*Itemset, numBaskets int, minConfidence float64) []*Rule {
+	counts := make(map[string]int, len(itemsets))
+	for _, s := range itemsets {
+		counts[itemsetKey(s.Items)] = s.Count
+	}
+	n := float64(numBaskets)
+
+	var rules []*Rule
+	for _, s := range itemsets {
+		k := len(s.Items)
+		if k < 2 {
+			continue
+		}
+		// every non-empty proper subset is an antecedent
+		for mask := 1; mask < 1<<uint(k)-1; mask++ {
+			var antecedent, consequent []int
+			for i, item := range s.Items {
+				if mask&(1<<uint(i)) != 0 {
+					antecedent = append(antecedent, item)
+				} else {
+					consequent = append(consequent, item)
+				}
+			}
+			ca, cc := counts[itemsetKey(antecedent)], counts[itemsetKey(consequent)]
+			if ca == 0 || cc == 0 {
+				continue
+			}
+			confidence := float64(s.Count) / float64(ca)
+			if confidence < minConfidence {
+				continue
+			}
+			rules = append(rules, &Rule{
+				Antecedent: antecedent,
+				Consequent: consequent,
+				Support:    float64(s.Count) / n,
+				Confidence: confidence,
+				Lift:       confidence / (float64(cc) / n),
+			})
+		}
+	}
+	sort.SliceStable(rules, func(a, b int) bool {
+		if rules[a].Confidence != rules[b].Confidence {
+			return rules[a].Confidence > rules[b].Confidence
+		}
+		return rules[a].Lift > rules[b].Lift
+	})
+	return rules
+}
+
+// The measure of a rule used to score the candidates
+type RuleScore int
+
+const (
+	ScoreByConfidence RuleScore = iota
+	ScoreByLift
+)
+
+// The options of the association rule recommender
+type RuleOptions struct {
+	Basket BasketOptions
+
+	Mining MiningOptions
+
+	// The minimum confidence of the rules
+	MinConfidence float64
+
+	// A candidate is scored by the best measure of the rules recommending it
+	Score RuleScore
+
+	// Whether to use FPGrowth or the reference Apriori
+	UseApriori bool
+}
+
+func DefaultRuleOptions() RuleOptions {
+	return RuleOptions{
+		Basket:        DefaultBasketOptions(),
+		Mining:        DefaultMiningOptions(),
+		MinConfidence: 0.1,
+		Score:         ScoreByConfidence,
+	}
+}
+
+// The "frequently bought together" recommender. The rules whose
+// antecedent is in the current basket fire, and their consequent
+// items not in the basket are the candidates.
+type RuleRecommender struct {
+	options RuleOptions
+
+	rules []*Rule
+
+	// The latest basket of each user in the training records
+	lastBaskets map[int]Basket
+}
+
+func NewRuleRecommender(options RuleOptions) *RuleRecommender {
+	m := new(RuleRecommender)
+	m.options = options
+	return m
+}
+
+// Mine the rules from the baskets of the records
+func (m *RuleRecommender) Fit(records []*core.Record) error {
+	if len(records) == 0 {
+		return core.ErrorEmptyRecords
+	}
+	baskets, owners := BasketsFromRecords(records, m.options.Basket)
+	m.lastBaskets = make(map[int]Basket)
+	for i, b := range baskets {
+		m.lastBaskets[owners[i]] = b
+	}
+	m.TrainBaskets(baskets)
+	return nil
+}
+
+// Mine the rules from the baskets
+func (m *RuleRecommender) TrainBaskets(baskets []Basket) {
+	var itemsets []*Itemset
+	if m.options.UseApriori {
+		itemsets = Apriori(baskets, m.options.Mining)
+	} else {
+		itemsets = FPGrowth(baskets, m.options.Mining)
+	}
+	m.rules = GenerateRules(itemsets, len(baskets), m.options.MinConfidence)
+}
+
+// Get the mined rules ordered by descending confidence
+func (m *RuleRecommender) Rules() []*Rule {
+	return m.rules
+}
+
+// Recommend the products to add to the basket
+func (m *RuleRecommender) RecommendForBasket(basket Basket, n int) []*core.ScoredProduct {
+	scores := make(map[int]float64)
+	for _, rule := range m.rules {
+		if !basket.ContainsAll(rule.Antecedent) {
+			continue
+		}
+		score := rule.Confidence
+		if m.options.Score == ScoreByLift {
+			score = rule.Lift
+		}
+		for _, item := range rule.Consequent {
+			if basket.Contains(item) {
+				continue
+			}
+			if s, ok := scores[item]; !ok || score > s {
+				scores[item] = score
+			}
+		}
+	}
+	return core.TopN(scores, n)
+}
+
+// Recommend for the latest basket of the user in the training records
+func (m *RuleRecommender) Recommend(userId int, context *core.Context, n int) []*core.ScoredProduct {
+	basket, ok := m.lastBaskets[userId]
+	if !ok {
+		return nil
+	}
+	return m.RecommendForBasket(basket, n)
+}
@@ -0,0 +1,181 @@
+// Copyright (c) 2014 Feng Wang <wffrank1987@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language
+
+package baseline
+
+import (
+	"github.com/numb3r3/gorec/core"
+	"github.com/numb3r3/gorec/math"
+)
+
+// The terms used by the baseline predictor
+type BaselineKind int
+
+const (
+	// r_ui = mu
+	GlobalMean BaselineKind = iota
+	// r_ui = mu + b_u
+	UserBias
+	// r_ui = mu + b_i
+	ItemBias
+	// r_ui = mu + b_u + b_i
+	UserItemBias
+)
+
+// The options of the baseline predictor
+type BaselineOptions struct {
+
+	// The terms of the predictor
+	Kind BaselineKind
+
+	// The regularization shrinking the biases of the users with few ratings
+	UserRegularization float64
+
+	// The regularization shrinking the biases of the items with few ratings
+	ItemRegularization float64
+
+	// The number of alternating estimations of the user and item biases
+	NumIterations int
+}
+
+func DefaultBaselineOptions() BaselineOptions {
+	return BaselineOptions{
+		Kind:               UserItemBias,
+		UserRegularization: 10,
+		ItemRegularization: 25,
+		NumIterations:      10,
+	}
+}
+
+// The baseline rating predictor with regularized biases (Koren, 2010)
+//
+//	b_i = sum_{u in R(i)} (r_ui - mu - b_u) / (lambda_i + |R(i)|)
+//	b_u = sum_{i in R(u)} (r_ui - mu - b_i) / (lambda_u + |R(u)|)
+type Baseline struct {
+	options BaselineOptions
+
+	mean float64
+
+	userBias, itemBias []float64
+
+	// The items rated by each user
+	userItems []map[int]bool
+}
+
+func NewBaseline(options BaselineOptions) *Baseline {
+	model := new(Baseline)
+	model.options = options
+	return model
+}
+
+// Fit on the explicit ratings of the records
+func (m *Baseline) Fit(records []*core.Record) error {
+	if len(records) == 0 {
+		return core.ErrorEmptyRecords
+	}
+	R, err := core.RatingMatrix(records, 0, 0)
+	if err != nil {
+		return err
+	}
+	return m.Train(R)
+}
+
+// Train on the user x item rating matrix
+func (m *Baseline) Train(R *math.SparseMatrix) error {
+	userItems := R.RowEntries()
+	itemUsers := R.ColEntries()
+	m.userBias = make([]float64, R.Rows())
+	m.itemBias = make([]float64, R.Cols())
+	m.userItems = make([]map[int]bool, R.Rows())
+
+	m.mean = 0
+	count := 0
+	for u, entries := range userItems {
+		m.userItems[u] = make(map[int]bool)
+		for _, e := range entries {
+			m.userItems[u][e.Index] = true
+			m.mean += e.Value
+			count++
+		}
+	}
+	if count == 0 {
+		return core.ErrorEmptyRecords
+	}
+	m.mean /= float64(count)
+
+	o := m.options
+	useUser := o.Kind == UserBias || o.Kind == UserItemBias
+	useItem := o.Kind == ItemBias || o.Kind == UserItemBias
+	iterations := 1
+	if useUser && useItem && o.NumIterations > 1 {
+		iterations = o.NumIterations
+	}
+	for iter := 0; iter < iterations; iter++ {
+		if useItem {
+			for i, entries := range itemUsers {
+				var s float64
+				for _, e := range entries {
+					s += e.Value - m.mean - m.userBias[e.Index]
+				}
+				m.itemBias[i] = s / (o.ItemRegularization + float64(len(entries)))
+			}
+		}
+		if useUser {
+			for u, entries := range userItems {
+				var s float64
+				for _, e := range entries {
+					s += e.Value - m.mean - m.itemBias[e.Index]
+				}
+				m.userBias[u] = s / (o.UserRegularization + float64(len(entries)))
+			}
+		}
+	}
+	return nil
+}
+
+// Predict the rating of the user on the item, the bias of an unknown
+// user or item is zero
+func (m *Baseline) Predict(userId, item int) float64 {
+	score := m.mean
+	if userId >= 0 && userId < len(m.userBias) {
+		score += m.userBias[userId]
+	}
+	if item >= 0 && item < len(m.itemBias) {
+		score += m.itemBias[item]
+	}
+	return score
+}
+
+// Recommend the items with the highest predicted ratings which the user has not rated
+func (m *Baseline) Recommend(userId int, context *core.Context, n int) []*core.ScoredProduct {
+	scores := make([]float64, len(m.itemBias))
+	for i := range scores {
+		scores[i] = m.Predict(userId, i)
+	}
+	return core.TopNSlice(scores, n, seenItems(m.userItems, userId))
+}
+
+// Get the global mean of the ratings
+func (m *Baseline) GlobalMean() float64 {
+	return m.mean
+}
+
+// Get the user biases
+func (m *Baseline) UserBiases() []float64 {
+	return m.userBias
+}
+
+// Get the item biases
+func (m *Baseline) ItemBiases() []float64 {
+	return m.itemBias
+}
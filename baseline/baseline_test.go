@@ -0,0 +1,120 @@
+// Copyright (c) 2014 Feng Wang <wffrank1987@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language
+
+package baseline
+
+import (
+	"fmt"
+	"github.com/numb3r3/gorec/core"
+	"github.com/numb3r3/gorec/utils"
+	"testing"
+	"time"
+)
+
+func ratings() []*core.Record {
+	now := time.Now()
+	return []*core.Record{
+		core.NewRating(0, 0, 5, now),
+		core.NewRating(0, 1, 3, now),
+		core.NewRating(1, 0, 4, now),
+		core.NewRating(1, 2, 2, now),
+	}
+}
+
+func TestBaseline(t *testing.T) {
+	// mu = 3.5
+	options := BaselineOptions{Kind: GlobalMean}
+	m := NewBaseline(options)
+	if err := m.Fit(ratings()); err != nil {
+		t.Fatal(err)
+	}
+	utils.ExpectNear(t, 3.5, m.Predict(0, 2), 1e-9)
+
+	// b_0 = (1.5 + 0.5) / (1 + 2), b_1 = (-0.5 - 1) / (1 + 1)
+	options = BaselineOptions{Kind: ItemBias, ItemRegularization: 1}
+	m = NewBaseline(options)
+	m.Fit(ratings())
+	utils.ExpectNear(t, 3.5+2.0/3, m.Predict(7, 0), 1e-9)
+	utils.ExpectNear(t, 3.5-0.5/2, m.Predict(0, 1), 1e-9)
+
+	// b_u0 = (1.5 - 0.5) / (0 + 2)
+	options = BaselineOptions{Kind: UserBias}
+	m = NewBaseline(options)
+	m.Fit(ratings())
+	utils.ExpectNear(t, 4, m.Predict(0, 2), 1e-9)
+	utils.ExpectNear(t, 3.5, m.Predict(-1, 2), 1e-9)
+
+	// without regularization the alternating estimation fits user 0
+	// and item 0 exactly: r_00 = 5 and r_01 = 3
+	options = BaselineOptions{Kind: UserItemBias, NumIterations: 200}
+	m = NewBaseline(options)
+	m.Fit(ratings())
+	utils.ExpectNear(t, 5, m.Predict(0, 0), 1e-6)
+	utils.ExpectNear(t, 3, m.Predict(0, 1), 1e-6)
+	utils.Expect(t, "2", fmt.Sprint(m.Recommend(0, nil, 5)[0].ProductId))
+
+	err := m.Fit([]*core.Record{core.NewRating(-1, 0, 1, time.Now())})
+	utils.Expect(t, core.ErrorIllegalId.Error(), err)
+}
+
+func TestMostPopular(t *testing.T) {
+	now := time.Now()
+	m := NewMostPopular()
+	m.Fit([]*core.Record{
+		core.NewEvent(0, 1, core.EventView, now),
+		core.NewEvent(1, 1, core.EventView, now),
+		core.NewEvent(1, 2, core.EventPurchase, now),
+		core.NewEvent(2, 0, core.EventView, now),
+		core.NewEvent(2, 4, core.EventView, now),
+	})
+	// counted by users, not by the event weights, and the item 3
+	// nobody interacted with is not padded in
+	utils.Expect(t, "[{1 2} {0 1} {2 1} {4 1}]", scored(m.Recommend(9, nil, 6)))
+	utils.Expect(t, "[{0 1} {4 1}]", scored(m.Recommend(1, nil, 6)))
+}
+
+func TestTrending(t *testing.T) {
+	now := time.Date(2014, 3, 1, 0, 0, 0, 0, time.UTC)
+	week := 7 * 24 * time.Hour
+	m := NewTrending(DefaultTrendingOptions())
+	m.Fit([]*core.Record{
+		core.NewEvent(0, 0, core.EventView, now.Add(-2*week)),
+		core.NewEvent(1, 0, core.EventView, now.Add(-2*week)),
+		core.NewEvent(2, 0, core.EventView, now.Add(-2*week)),
+		core.NewEvent(0, 1, core.EventView, now),
+	})
+	// 3 old views decayed to 3/4 lose to a fresh one
+	utils.Expect(t, "[{1 1} {0 0.75}]", scored(m.Recommend(9, nil, 2)))
+	// the item 2 is only reached by an event without weight
+	weights := core.DefaultEventWeights()
+	weights[core.EventClick] = 0
+	options := DefaultTrendingOptions()
+	options.Weights = weights
+	m = NewTrending(options)
+	m.Fit([]*core.Record{
+		core.NewEvent(0, 0, core.EventView, now),
+		core.NewEvent(0, 2, core.EventClick, now),
+	})
+	utils.Expect(t, "[{0 1}]", scored(m.Recommend(9, nil, 5)))
+
+	err := NewTrending(DefaultTrendingOptions()).Fit([]*core.Record{core.NewEvent(0, -1, core.EventView, now)})
+	utils.Expect(t, core.ErrorIllegalId.Error(), err)
+}
+
+func scored(products []*core.ScoredProduct) string {
+	s := make([]string, len(products))
+	for i, p := range products {
+		s[i] = fmt.Sprint(*p)
+	}
+	return fmt.Sprint(s)
+}
@@ -0,0 +1,179 @@
+// Copyright (c) 2014 Feng Wang <wffrank1987@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language
+
+package baseline
+
+import (
+	gomath "math"
+	"time"
+
+	"github.com/numb3r3/gorec/core"
+	"github.com/numb3r3/gorec/math"
+)
+
+// Recommend the items interacted with by the most users. It works for any
+// user, including the unknown ones, so it is a common cold-start fallback.
+type MostPopular struct {
+	// The number of users who interacted with each item
+	counts []float64
+
+	// The items of each user
+	userItems []map[int]bool
+}
+
+func NewMostPopular() *MostPopular {
+	return new(MostPopular)
+}
+
+func (m *MostPopular) Fit(records []*core.Record) error {
+	if len(records) == 0 {
+		return core.ErrorEmptyRecords
+	}
+	R, err := core.InteractionMatrix(records, nil, 0, 0)
+	if err != nil {
+		return err
+	}
+	return m.Train(R)
+}
+
+// Train on the user x item interaction matrix
+func (m *MostPopular) Train(R *math.SparseMatrix) error {
+	m.counts = make([]float64, R.Cols())
+	m.userItems = make([]map[int]bool, R.Rows())
+	R.Each(func(u, i int, v float64) {
+		m.counts[i]++
+		if m.userItems[u] == nil {
+			m.userItems[u] = make(map[int]bool)
+		}
+		m.userItems[u][i] = true
+	})
+	return nil
+}
+
+// Get the number of users who interacted with the item
+func (m *MostPopular) Predict(userId, item int) float64 {
+	if item < 0 || item >= len(m.counts) {
+		return 0
+	}
+	return m.counts[item]
+}
+
+func (m *MostPopular) Recommend(userId int, context *core.Context, n int) []*core.ScoredProduct {
+	return topPositive(m.counts, n, seenItems(m.userItems, userId))
+}
+
+// The options of the trending recommender
+type TrendingOptions struct {
+
+	// The weight of an interaction halves every HalfLife
+	HalfLife time.Duration
+
+	// The time at which the ages of the interactions are measured,
+	// the time of the latest record if zero
+	Now time.Time
+
+	// The weights of the events, nil for the default weights
+	Weights core.EventWeights
+}
+
+func DefaultTrendingOptions() TrendingOptions {
+	return TrendingOptions{HalfLife: 7 * 24 * time.Hour}
+}
+
+// Recommend the items with the most recent interactions. Each record
+// contributes its event weight decayed exponentially by its age:
+//
+//	score_i = sum_r w_r * 2^(-age_r / halfLife)
+type Trending struct {
+	options TrendingOptions
+
+	scores []float64
+
+	userItems []map[int]bool
+}
+
+func NewTrending(options TrendingOptions) *Trending {
+	model := new(Trending)
+	model.options = options
+	return model
+}
+
+func (m *Trending) Fit(records []*core.Record) error {
+	if len(records) == 0 {
+		return core.ErrorEmptyRecords
+	}
+	for _, r := range records {
+		if r.UserId < 0 || r.ProductId < 0 {
+			return core.ErrorIllegalId
+		}
+	}
+	now := m.options.Now
+	if now.IsZero() {
+		for _, r := range records {
+			if r.Timestamp.After(now) {
+				now = r.Timestamp
+			}
+		}
+	}
+	rows, cols := core.RecordsDimension(records)
+	m.scores = make([]float64, cols)
+	m.userItems = make([]map[int]bool, rows)
+	halfLife := m.options.HalfLife.Hours()
+	for _, r := range records {
+		decay := 1.0
+		if halfLife > 0 && !r.Timestamp.IsZero() {
+			age := now.Sub(r.Timestamp).Hours()
+			if age < 0 {
+				age = 0
+			}
+			decay = gomath.Exp2(-age / halfLife)
+		}
+		m.scores[r.ProductId] += r.Weight(m.options.Weights) * decay
+		if m.userItems[r.UserId] == nil {
+			m.userItems[r.UserId] = make(map[int]bool)
+		}
+		m.userItems[r.UserId][r.ProductId] = true
+	}
+	return nil
+}
+
+// Get the decayed popularity of the item
+func (m *Trending) Predict(userId, item int) float64 {
+	if item < 0 || item >= len(m.scores) {
+		return 0
+	}
+	return m.scores[item]
+}
+
+func (m *Trending) Recommend(userId int, context *core.Context, n int) []*core.ScoredProduct {
+	return topPositive(m.scores, n, seenItems(m.userItems, userId))
+}
+
+// Get the top-n items with positive scores which are not in exclude,
+// the items nobody interacted with are never recommended
+func topPositive(scores []float64, n int, exclude map[int]bool) []*core.ScoredProduct {
+	positive := make(map[int]float64)
+	for i, score := range scores {
+		if score > 0 && !exclude[i] {
+			positive[i] = score
+		}
+	}
+	return core.TopN(positive, n)
+}
+
+func seenItems(userItems []map[int]bool, userId int) map[int]bool {
+	if userId < 0 || userId >= len(userItems) {
+		return nil
+	}
+	return userItems[userId]
+}
@@ -0,0 +1,262 @@
+// Copyright (c) 2014 Feng Wang <wffrank1987@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language
+
+package content
+
+import (
+	gomath "math"
+
+	"github.com/numb3r3/gorec/core"
+	"github.com/numb3r3/gorec/utils"
+)
+
+// The options of the content-based recommender
+type ContentOptions struct {
+	Tokenizer TokenizerOptions
+
+	// The weights of the terms from the title, the description and the
+	// tags of the products. A tag is one term no matter how many words.
+	TitleWeight, DescriptionWeight, TagWeight float64
+
+	// The terms in fewer products are dropped from the vocabulary
+	MinDocumentFrequency int
+
+	// Whether to use 1 + log(tf) instead of the raw term frequency,
+	// applied to the count in each field before the field weighting
+	SublinearTF bool
+
+	// The weights of the events building the user profiles, nil for
+	// the default weights
+	Weights core.EventWeights
+}
+
+func DefaultContentOptions() ContentOptions {
+	return ContentOptions{
+		Tokenizer:            DefaultTokenizerOptions(),
+		TitleWeight:          2,
+		DescriptionWeight:    1,
+		TagWeight:            1,
+		MinDocumentFrequency: 1,
+		SublinearTF:          true,
+	}
+}
+
+// A sparse vector of term id -> weight
+type termVector map[int]float64
+
+func (v termVector) normalize() {
+	var s float64
+	for _, w := range v {
+		s += w * w
+	}
+	if s == 0 {
+		return
+	}
+	s = gomath.Sqrt(s)
+	for id, w := range v {
+		v[id] = w / s
+	}
+}
+
+func (v termVector) dot(o termVector) float64 {
+	if len(o) < len(v) {
+		v, o = o, v
+	}
+	var s float64
+	for id, w := range v {
+		s += w * o[id]
+	}
+	return s
+}
+
+// The content-based recommender. The products are represented by the
+// L2-normalized TF-IDF vectors of their text, a user profile is the
+// normalized sum of the vectors of the user's products weighted by the
+// events, and the products are ranked by the cosine with the profile.
+// Since only the text is needed, the new products with no interaction
+// are recommended as well.
+type ContentBased struct {
+	options ContentOptions
+
+	catalog *core.Catalog
+
+	tokenizer *Tokenizer
+
+	// The term vocabulary
+	vocabulary *utils.Dictionary
+
+	// The inverse document frequency of each term id
+	idf map[int]float64
+
+	// product id -> TF-IDF vector
+	itemVectors map[int]termVector
+
+	// user id -> profile vector
+	profiles map[int]termVector
+
+	// user id -> the products of the user
+	userItems map[int]map[int]bool
+}
+
+// Create the model on the products of the catalog, ErrorNilCatalog is
+// returned if the catalog is nil
+func NewContentBased(catalog *core.Catalog, options ContentOptions) (*ContentBased, error) {
+	if catalog == nil {
+		return nil, ErrorNilCatalog
+	}
+	m := new(ContentBased)
+	m.options = options
+	m.catalog = catalog
+	m.tokenizer = NewTokenizer(options.Tokenizer)
+	return m, nil
+}
+
+// Index the products of the catalog into the TF-IDF vectors. It is
+// called by Fit, and can be called again to pick up new products.
+func (m *ContentBased) Index() {
+	o := m.options
+	products := m.catalog.Products()
+	m.vocabulary = utils.NewDictionary(0)
+	m.idf = make(map[int]float64)
+	m.itemVectors = make(map[int]termVector, len(products))
+
+	// term frequencies of each product, and document frequencies
+	tfs := make(map[int]map[string]float64, len(products))
+	df := make(map[string]int)
+	for _, p := range products {
+		tf := make(map[string]float64)
+		m.addTerms(tf, m.tokenizer.Tokenize(p.Title), o.TitleWeight)
+		m.addTerms(tf, m.tokenizer.Tokenize(p.Description), o.DescriptionWeight)
+		tags := make([]string, len(p.Tags))
+		for i, tag := range p.Tags {
+			tags[i] = "tag:" + tag
+		}
+		m.addTerms(tf, tags, o.TagWeight)
+		for term, f := range tf {
+			if f <= 0 {
+				delete(tf, term)
+				continue
+			}
+			df[term]++
+		}
+		tfs[p.Id] = tf
+	}
+
+	numDocs := float64(len(products))
+	for id, tf := range tfs {
+		v := make(termVector, len(tf))
+		for term, f := range tf {
+			if df[term] < o.MinDocumentFrequency {
+				continue
+			}
+			termId := m.vocabulary.AddName(term)
+			if _, ok := m.idf[termId]; !ok {
+				m.idf[termId] = gomath.Log((1+numDocs)/(1+float64(df[term]))) + 1
+			}
+			v[termId] = f * m.idf[termId]
+		}
+		v.normalize()
+		m.itemVectors[id] = v
+	}
+}
+
+// Add the terms of one field to the term frequencies, weighted by the
+// weight of the field
+func (m *ContentBased) addTerms(tf map[string]float64, terms []string, weight float64) {
+	counts := make(map[string]float64)
+	for _, term := range terms {
+		counts[term]++
+	}
+	for term, count := range counts {
+		if m.options.SublinearTF {
+			count = 1 + gomath.Log(count)
+		}
+		tf[term] += weight * count
+	}
+}
+
+// Index the catalog and build the user profiles from the records
+func (m *ContentBased) Fit(records []*core.Record) error {
+	if len(records) == 0 {
+		return core.ErrorEmptyRecords
+	}
+	m.Index()
+	m.profiles = make(map[int]termVector)
+	m.userItems = make(map[int]map[int]bool)
+	for _, r := range records {
+		if m.userItems[r.UserId] == nil {
+			m.userItems[r.UserId] = make(map[int]bool)
+			m.profiles[r.UserId] = make(termVector)
+		}
+		m.userItems[r.UserId][r.ProductId] = true
+		w := r.Weight(m.options.Weights)
+		profile := m.profiles[r.UserId]
+		for id, v := range m.itemVectors[r.ProductId] {
+			profile[id] += w * v
+		}
+	}
+	for _, profile := range m.profiles {
+		profile.normalize()
+	}
+	return nil
+}
+
+// Get the term vocabulary
+func (m *ContentBased) GetVocabulary() *utils.Dictionary {
+	return m.vocabulary
+}
+
+// Get the TF-IDF vector of the product as term -> weight
+func (m *ContentBased) ItemTerms(productId int) map[string]float64 {
+	terms := make(map[string]float64)
+	for id, w := range m.itemVectors[productId] {
+		terms[m.vocabulary.GetNameFromId(id)] = w
+	}
+	return terms
+}
+
+// Get the cosine similarity of the contents of the two products
+func (m *ContentBased) Similarity(a, b int) float64 {
+	return m.itemVectors[a].dot(m.itemVectors[b])
+}
+
+// Recommend the products most similar to the profile of the user
+func (m *ContentBased) Recommend(userId int, context *core.Context, n int) []*core.ScoredProduct {
+	profile, ok := m.profiles[userId]
+	if !ok {
+		return nil
+	}
+	return m.rank(profile, m.userItems[userId], n)
+}
+
+// Get the n products with the most similar contents to the product
+func (m *ContentBased) SimilarItems(productId, n int) []*core.ScoredProduct {
+	v, ok := m.itemVectors[productId]
+	if !ok {
+		return nil
+	}
+	return m.rank(v, map[int]bool{productId: true}, n)
+}
+
+func (m *ContentBased) rank(v termVector, exclude map[int]bool, n int) []*core.ScoredProduct {
+	scores := make(map[int]float64)
+	for id, iv := range m.itemVectors {
+		if exclude[id] {
+			continue
+		}
+		if s := v.dot(iv); s > 0 {
+			scores[id] = s
+		}
+	}
+	return core.TopN(scores, n)
+}
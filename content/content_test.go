@@ -0,0 +1,93 @@
+// Copyright (c) 2014 Feng Wang <wffrank1987@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language
+
+package content
+
+import (
+	"github.com/numb3r3/gorec/core"
+	"github.com/numb3r3/gorec/utils"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTokenizer(t *testing.T) {
+	options := DefaultTokenizerOptions()
+	options.NGrams = 2
+	tokenizer := NewTokenizer(options)
+	terms := tokenizer.Tokenize("The Red Shoes of a dancer, x red-shoes")
+	// "of a" and "x" break the bigrams
+	utils.Expect(t, "red shoes dancer red shoes red_shoes red_shoes", strings.Join(terms, " "))
+
+	options.NGrams = 3
+	terms = NewTokenizer(options).Tokenize("running shoes for trail running")
+	utils.Expect(t, "running shoes trail running running_shoes trail_running", strings.Join(terms, " "))
+}
+
+func catalog() *core.Catalog {
+	catalog := core.NewCatalog(nil)
+	catalog.AddProduct(&core.Product{Id: 0, Title: "red running shoes", Tags: []string{"sport"}})
+	catalog.AddProduct(&core.Product{Id: 1, Title: "blue running shoes", Tags: []string{"sport"}})
+	catalog.AddProduct(&core.Product{Id: 2, Title: "chocolate cake", Description: "sweet cake with chocolate"})
+	catalog.AddProduct(&core.Product{Id: 3, Title: "lemon cake", Description: "sweet"})
+	return catalog
+}
+
+func TestContentBased(t *testing.T) {
+	model, err := NewContentBased(catalog(), DefaultContentOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = model.Fit([]*core.Record{core.NewEvent(0, 0, core.EventClick, time.Time{})})
+	if err != nil {
+		t.Fatal(err)
+	}
+	utils.ExpectNear(t, 1, model.Similarity(0, 0), 1e-9)
+	utils.Expect(t, "true", model.Similarity(0, 1) > model.Similarity(0, 2))
+	utils.ExpectNear(t, 0, model.Similarity(0, 2), 1e-12)
+
+	similar := model.SimilarItems(2, 3)
+	utils.Expect(t, "1", len(similar))
+	utils.Expect(t, "3", similar[0].ProductId)
+
+	// the new product 1 is recommended by its content alone
+	result := model.Recommend(0, nil, 3)
+	utils.Expect(t, "1", len(result))
+	utils.Expect(t, "1", result[0].ProductId)
+	utils.Expect(t, "0", len(model.Recommend(1, nil, 3)))
+
+	_, err = NewContentBased(nil, DefaultContentOptions())
+	utils.Expect(t, ErrorNilCatalog.Error(), err)
+}
+
+func TestSublinearTF(t *testing.T) {
+	options := DefaultContentOptions()
+	options.DescriptionWeight = 0.3
+	model, err := NewContentBased(catalog(), options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	model.Index()
+	// the description terms with a weight below 1/e stay positive
+	for _, p := range catalog().Products() {
+		for term, w := range model.ItemTerms(p.Id) {
+			if w <= 0 {
+				t.Errorf("term %s of product %d has weight %f", term, p.Id, w)
+			}
+		}
+	}
+	terms := model.ItemTerms(2)
+	// "cake" is once in the title and once in the description, 2 + 0.3,
+	// and "sweet" once in the description, 0.3, both are in 2 products
+	utils.ExpectNear(t, 2.3/0.3, terms["cake"]/terms["sweet"], 1e-9)
+}
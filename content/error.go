@@ -0,0 +1,42 @@
+// Copyright (c) 2014 Feng Wang <wffrank1987@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language
+
+package content
+
+import (
+	"fmt"
+)
+
+const (
+	// The model has no catalog to index
+	errorNilCatalog = iota
+)
+
+type error_ int
+
+func (e error_) Error() string {
+	switch e {
+	case errorNilCatalog:
+		return "Catalog is nil"
+	}
+	return fmt.Sprintf("Unknown error code %d", e)
+}
+
+func (e error_) String() string {
+	return e.Error()
+}
+
+var (
+	// The model has no catalog to index
+	ErrorNilCatalog error_ = error_(errorNilCatalog)
+)
@@ -0,0 +1,112 @@
+// Copyright (c) 2014 Feng Wang <wffrank1987@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language
+
+package content
+
+import (
+	"strings"
+	"unicode"
+)
+
+// The options of the tokenizer
+type TokenizerOptions struct {
+
+	// Whether to lowercase the text
+	Lowercase bool
+
+	// The tokens shorter than MinLength runes are dropped
+	MinLength int
+
+	// The tokens to drop, matched after lowercasing
+	StopWords map[string]bool
+
+	// The n-grams of the lengths 1 to NGrams are produced, joined by "_".
+	// An n-gram never spans a dropped token.
+	NGrams int
+}
+
+func DefaultTokenizerOptions() TokenizerOptions {
+	return TokenizerOptions{
+		Lowercase: true,
+		MinLength: 2,
+		StopWords: EnglishStopWords(),
+		NGrams:    1,
+	}
+}
+
+// Split the text into the terms on the non-letter and non-digit runes
+type Tokenizer struct {
+	options TokenizerOptions
+}
+
+func NewTokenizer(options TokenizerOptions) *Tokenizer {
+	t := new(Tokenizer)
+	t.options = options
+	return t
+}
+
+// Get the terms (the tokens and their n-grams) of the text in order
+func (t *Tokenizer) Tokenize(text string) []string {
+	o := t.options
+	if o.Lowercase {
+		text = strings.ToLower(text)
+	}
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	// the runs of the kept tokens, the n-grams never span a dropped token
+	var runs [][]string
+	var run []string
+	for _, w := range words {
+		if len([]rune(w)) < o.MinLength || o.StopWords[strings.ToLower(w)] {
+			if len(run) > 0 {
+				runs = append(runs, run)
+				run = nil
+			}
+			continue
+		}
+		run = append(run, w)
+	}
+	if len(run) > 0 {
+		runs = append(runs, run)
+	}
+
+	var terms []string
+	for _, tokens := range runs {
+		terms = append(terms, tokens...)
+	}
+	for n := 2; n <= o.NGrams; n++ {
+		for _, tokens := range runs {
+			for i := 0; i+n <= len(tokens); i++ {
+				terms = append(terms, strings.Join(tokens[i:i+n], "_"))
+			}
+		}
+	}
+	return terms
+}
+
+// Get a small set of the common English stop words
+func EnglishStopWords() map[string]bool {
+	words := []string{
+		"a", "an", "and", "are", "as", "at", "be", "but", "by", "for",
+		"from", "has", "have", "if", "in", "into", "is", "it", "its", "of",
+		"on", "or", "our", "so", "such", "that", "the", "their", "then",
+		"there", "these", "this", "to", "was", "we", "were", "will", "with",
+		"you", "your",
+	}
+	stopWords := make(map[string]bool, len(words))
+	for _, w := range words {
+		stopWords[w] = true
+	}
+	return stopWords
+}
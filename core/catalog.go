@@ -0,0 +1,173 @@
+// Copyright (c) 2014 Feng Wang <wffrank1987@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language
+
+package core
+
+import (
+	"sort"
+)
+
+// The in-memory store of the products, indexed by id, category and tag
+type Catalog struct {
+	products map[int]*Product
+
+	taxonomy *Taxonomy
+
+	// category name -> ids of the products directly in the category
+	byCategory map[string][]int
+
+	// tag -> ids of the products with the tag
+	byTag map[string][]int
+
+	// id -> the category and tags the product is indexed under, which
+	// can differ from the fields of the product after it is modified
+	indexed map[int]indexEntry
+}
+
+type indexEntry struct {
+	category string
+
+	tags []string
+}
+
+// Create a catalog with the taxonomy, an empty taxonomy is used if it is nil
+func NewCatalog(taxonomy *Taxonomy) *Catalog {
+	if taxonomy == nil {
+		taxonomy = NewTaxonomy()
+	}
+	catalog := new(Catalog)
+	catalog.products = make(map[int]*Product)
+	catalog.taxonomy = taxonomy
+	catalog.byCategory = make(map[string][]int)
+	catalog.byTag = make(map[string][]int)
+	catalog.indexed = make(map[int]indexEntry)
+	return catalog
+}
+
+// Get the taxonomy of the catalog
+func (c *Catalog) GetTaxonomy() *Taxonomy {
+	return c.taxonomy
+}
+
+// Add the product into the catalog, the product with the same id is replaced
+func (c *Catalog) AddProduct(p *Product) error {
+	if p.Id < 0 {
+		return ErrorIllegalId
+	}
+	if p.Category != "" && c.taxonomy.GetCategory(p.Category) == nil {
+		return ErrorUnknownCategory
+	}
+	if _, ok := c.products[p.Id]; ok {
+		c.RemoveProduct(p.Id)
+	}
+	c.products[p.Id] = p
+	entry := indexEntry{category: p.Category}
+	if p.Category != "" {
+		c.byCategory[p.Category] = append(c.byCategory[p.Category], p.Id)
+	}
+	for _, tag := range p.Tags {
+		if containsString(entry.tags, tag) {
+			continue
+		}
+		entry.tags = append(entry.tags, tag)
+		c.byTag[tag] = append(c.byTag[tag], p.Id)
+	}
+	c.indexed[p.Id] = entry
+	return nil
+}
+
+// Remove the product from the catalog
+func (c *Catalog) RemoveProduct(id int) bool {
+	if _, ok := c.products[id]; !ok {
+		return false
+	}
+	entry := c.indexed[id]
+	delete(c.products, id)
+	delete(c.indexed, id)
+	if entry.category != "" {
+		c.byCategory[entry.category] = removeId(c.byCategory[entry.category], id)
+	}
+	for _, tag := range entry.tags {
+		c.byTag[tag] = removeId(c.byTag[tag], id)
+	}
+	return true
+}
+
+// Get the number of products in the catalog
+func (c *Catalog) NumProducts() int {
+	return len(c.products)
+}
+
+// Get the product by id, nil if it does not exist
+func (c *Catalog) GetProduct(id int) *Product {
+	return c.products[id]
+}
+
+// Get all of the products ordered by id
+func (c *Catalog) Products() []*Product {
+	ids := make([]int, 0, len(c.products))
+	for id := range c.products {
+		ids = append(ids, id)
+	}
+	return c.lookup(ids)
+}
+
+// Get the products in the subtree of the category ordered by id
+func (c *Catalog) ProductsInCategory(category string) []*Product {
+	var ids []int
+	for _, name := range c.taxonomy.Subtree(category) {
+		ids = append(ids, c.byCategory[name]...)
+	}
+	return c.lookup(ids)
+}
+
+// Get the products with the tag ordered by id
+func (c *Catalog) ProductsWithTag(tag string) []*Product {
+	return c.lookup(append([]int(nil), c.byTag[tag]...))
+}
+
+// Whether the product is in the subtree of the category
+func (c *Catalog) InCategory(id int, category string) bool {
+	entry, ok := c.indexed[id]
+	if !ok || entry.category == "" {
+		return false
+	}
+	return c.taxonomy.IsUnder(entry.category, category)
+}
+
+func (c *Catalog) lookup(ids []int) []*Product {
+	sort.Ints(ids)
+	products := make([]*Product, 0, len(ids))
+	for _, id := range ids {
+		products = append(products, c.products[id])
+	}
+	return products
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func removeId(ids []int, id int) []int {
+	for i, v := range ids {
+		if v == id {
+			return append(ids[:i], ids[i+1:]...)
+		}
+	}
+	return ids
+}
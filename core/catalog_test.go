@@ -0,0 +1,74 @@
+// Copyright (c) 2014 Feng Wang <wffrank1987@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language
+
+package core
+
+import (
+	"fmt"
+	"github.com/numb3r3/gorec/utils"
+	"testing"
+)
+
+func productIds(products []*Product) []int {
+	ids := make([]int, len(products))
+	for i, p := range products {
+		ids[i] = p.Id
+	}
+	return ids
+}
+
+func TestTaxonomy(t *testing.T) {
+	taxonomy := NewTaxonomy()
+	taxonomy.AddCategory("a", "")
+	taxonomy.AddCategory("a1", "a")
+	taxonomy.AddCategory("a11", "a1")
+	_, err := taxonomy.AddCategory("a", "")
+	utils.Expect(t, ErrorDuplicateCategory.Error(), err)
+	_, err = taxonomy.AddCategory("x", "missing")
+	utils.Expect(t, ErrorUnknownCategory.Error(), err)
+
+	utils.Expect(t, "[a1 a]", taxonomy.Ancestors("a11"))
+	utils.Expect(t, "[a a1 a11]", taxonomy.Subtree("a"))
+	utils.Expect(t, "true false", fmt.Sprint(taxonomy.IsUnder("a11", "a"), taxonomy.IsUnder("a", "a1")))
+}
+
+func TestCatalog(t *testing.T) {
+	taxonomy := NewTaxonomy()
+	taxonomy.AddCategory("a", "")
+	taxonomy.AddCategory("b", "")
+	catalog := NewCatalog(taxonomy)
+
+	p := NewProduct(1, "one")
+	p.Category = "a"
+	p.Tags = []string{"x", "x", "y"}
+	catalog.AddProduct(p)
+	catalog.AddProduct(&Product{Id: 2, Category: "a"})
+	utils.Expect(t, "[1]", productIds(catalog.ProductsWithTag("x")))
+	utils.Expect(t, "[1 2]", productIds(catalog.ProductsInCategory("a")))
+
+	// re-adding the modified product moves it out of its old index entries
+	p.Category = "b"
+	p.Tags = []string{"z"}
+	catalog.AddProduct(p)
+	utils.Expect(t, "[2]", productIds(catalog.ProductsInCategory("a")))
+	utils.Expect(t, "[1]", productIds(catalog.ProductsInCategory("b")))
+	utils.Expect(t, "[]", productIds(catalog.ProductsWithTag("x")))
+	utils.Expect(t, "[1]", productIds(catalog.ProductsWithTag("z")))
+
+	utils.Expect(t, ErrorUnknownCategory.Error(), catalog.AddProduct(&Product{Id: 3, Category: "c"}))
+	utils.Expect(t, ErrorIllegalId.Error(), catalog.AddProduct(&Product{Id: -1}))
+
+	catalog.RemoveProduct(1)
+	utils.Expect(t, "1", catalog.NumProducts())
+	utils.Expect(t, "[]", productIds(catalog.ProductsInCategory("b")))
+}
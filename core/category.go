@@ -0,0 +1,119 @@
+// Copyright (c) 2014 Feng Wang <wffrank1987@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language
+
+package core
+
+// A node of the category tree
+type Category struct {
+	Name string
+
+	// The parent category, nil for the root categories
+	Parent *Category
+
+	Children []*Category
+}
+
+// Get the depth of the category, the root categories have depth 0
+func (c *Category) Depth() int {
+	depth := 0
+	for p := c.Parent; p != nil; p = p.Parent {
+		depth++
+	}
+	return depth
+}
+
+// The hierarchical category tree (forest) of the products
+type Taxonomy struct {
+	categories map[string]*Category
+
+	roots []*Category
+}
+
+func NewTaxonomy() *Taxonomy {
+	taxonomy := new(Taxonomy)
+	taxonomy.categories = make(map[string]*Category)
+	return taxonomy
+}
+
+// Add a category under the parent, which is a root category if the parent is empty
+func (t *Taxonomy) AddCategory(name, parent string) (*Category, error) {
+	if _, ok := t.categories[name]; ok {
+		return nil, ErrorDuplicateCategory
+	}
+	c := &Category{Name: name}
+	if parent == "" {
+		t.roots = append(t.roots, c)
+	} else {
+		p, ok := t.categories[parent]
+		if !ok {
+			return nil, ErrorUnknownCategory
+		}
+		c.Parent = p
+		p.Children = append(p.Children, c)
+	}
+	t.categories[name] = c
+	return c, nil
+}
+
+// Get the category by name, nil if it does not exist
+func (t *Taxonomy) GetCategory(name string) *Category {
+	return t.categories[name]
+}
+
+// Get the root categories
+func (t *Taxonomy) Roots() []*Category {
+	return t.roots
+}
+
+// Get the names of the ancestors of the category from the parent up to the root
+func (t *Taxonomy) Ancestors(name string) []string {
+	var ancestors []string
+	c, ok := t.categories[name]
+	if !ok {
+		return nil
+	}
+	for p := c.Parent; p != nil; p = p.Parent {
+		ancestors = append(ancestors, p.Name)
+	}
+	return ancestors
+}
+
+// Get the names of the categories in the subtree rooted at the category,
+// including the category itself
+func (t *Taxonomy) Subtree(name string) []string {
+	c, ok := t.categories[name]
+	if !ok {
+		return nil
+	}
+	var names []string
+	stack := []*Category{c}
+	for len(stack) > 0 {
+		c = stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		names = append(names, c.Name)
+		for i := len(c.Children) - 1; i >= 0; i-- {
+			stack = append(stack, c.Children[i])
+		}
+	}
+	return names
+}
+
+// Whether the category is the ancestor or the category itself
+func (t *Taxonomy) IsUnder(name, ancestor string) bool {
+	for c := t.categories[name]; c != nil; c = c.Parent {
+		if c.Name == ancestor {
+			return true
+		}
+	}
+	return false
+}
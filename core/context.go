@@ -12,3 +12,175 @@
 //    See the License for the specific language
 
 package core
+
+import (
+	"math"
+	"strings"
+	"time"
+)
+
+// The device issuing the recommendation request
+type Device string
+
+const (
+	DeviceUnknown Device = ""
+	DeviceDesktop Device = "desktop"
+	DeviceMobile  Device = "mobile"
+	DeviceTablet  Device = "tablet"
+	DeviceTV      Device = "tv"
+)
+
+// The part of the day in the local time
+type TimeOfDay int
+
+const (
+	// [0:00, 6:00)
+	Night TimeOfDay = iota
+	// [6:00, 12:00)
+	Morning
+	// [12:00, 18:00)
+	Afternoon
+	// [18:00, 24:00)
+	Evening
+)
+
+func (t TimeOfDay) String() string {
+	switch t {
+	case Night:
+		return "night"
+	case Morning:
+		return "morning"
+	case Afternoon:
+		return "afternoon"
+	case Evening:
+		return "evening"
+	}
+	return "unknown"
+}
+
+// A geographic coordinate in degrees
+type GeoPoint struct {
+	Latitude  float64
+	Longitude float64
+}
+
+// The mean radius of the earth in kilometers
+const earthRadiusKm = 6371.0
+
+// Get the great-circle distance in kilometers by the haversine formula
+func (p GeoPoint) DistanceKm(o GeoPoint) float64 {
+	lat1 := p.Latitude * math.Pi / 180
+	lat2 := o.Latitude * math.Pi / 180
+	dLat := lat2 - lat1
+	dLon := (o.Longitude - p.Longitude) * math.Pi / 180
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Sqrt(a))
+}
+
+// The request-time context of a recommendation
+type Context struct {
+	// The time when the request is issued, in the local time of the user
+	Time time.Time
+
+	// The device issuing the request
+	Device Device
+
+	// The locale of the request (e.g. "en_US")
+	Locale string
+
+	// Where the request is issued
+	// Can be nil if unknown
+	Location *GeoPoint
+
+	// Other context attributes
+	// Can be nil
+	Attributes map[string]string
+}
+
+// Create a context at the time
+func NewContext(t time.Time) *Context {
+	return &Context{Time: t, Attributes: make(map[string]string)}
+}
+
+// Get the part of the day of the request
+func (c *Context) TimeOfDay() TimeOfDay {
+	return TimeOfDay(c.Time.Hour() / 6)
+}
+
+// Get the day of the week of the request
+func (c *Context) DayOfWeek() time.Weekday {
+	return c.Time.Weekday()
+}
+
+// Whether the request is issued on Saturday or Sunday
+func (c *Context) IsWeekend() bool {
+	d := c.DayOfWeek()
+	return d == time.Saturday || d == time.Sunday
+}
+
+// Get the context of the record, a context with only the time of the
+// record is built if the record has no context
+func RecordContext(r *Record) *Context {
+	if r.Context != nil {
+		return r.Context
+	}
+	if r.Timestamp.IsZero() {
+		return nil
+	}
+	return &Context{Time: r.Timestamp}
+}
+
+// Map a context into the name of its segment, the empty name means the
+// context belongs to no segment
+type ContextSegmenter func(context *Context) string
+
+// Segment the contexts by the part of the day
+func SegmentByTimeOfDay(context *Context) string {
+	if context == nil || context.Time.IsZero() {
+		return ""
+	}
+	return context.TimeOfDay().String()
+}
+
+// Segment the contexts into "weekday" and "weekend"
+func SegmentByWeekend(context *Context) string {
+	if context == nil || context.Time.IsZero() {
+		return ""
+	}
+	if context.IsWeekend() {
+		return "weekend"
+	}
+	return "weekday"
+}
+
+// Segment the contexts by the device
+func SegmentByDevice(context *Context) string {
+	if context == nil {
+		return ""
+	}
+	return string(context.Device)
+}
+
+// Segment the contexts by the locale
+func SegmentByLocale(context *Context) string {
+	if context == nil {
+		return ""
+	}
+	return context.Locale
+}
+
+// Combine the segmenters into the cross segments (e.g. "mobile|evening"),
+// the context belongs to no segment if any of the segmenters says so
+func CombineSegmenters(segmenters ...ContextSegmenter) ContextSegmenter {
+	return func(context *Context) string {
+		names := make([]string, len(segmenters))
+		for i, segmenter := range segmenters {
+			names[i] = segmenter(context)
+			if names[i] == "" {
+				return ""
+			}
+		}
+		return strings.Join(names, "|")
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 2014 Feng Wang <wffrank1987@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language
+
+package core
+
+import (
+	"fmt"
+	"github.com/numb3r3/gorec/utils"
+	"testing"
+	"time"
+)
+
+func TestDistanceKm(t *testing.T) {
+	paris := GeoPoint{48.8566, 2.3522}
+	london := GeoPoint{51.5074, -0.1278}
+	utils.ExpectNear(t, 343.5, paris.DistanceKm(london), 1)
+	utils.ExpectNear(t, 0, paris.DistanceKm(paris), 1e-9)
+	// a quarter of the equator
+	utils.ExpectNear(t, 10007.5, GeoPoint{0, 0}.DistanceKm(GeoPoint{0, 90}), 0.1)
+}
+
+func TestSegmenters(t *testing.T) {
+	// 2014-03-01 is a Saturday
+	c := NewContext(time.Date(2014, 3, 1, 19, 30, 0, 0, time.UTC))
+	c.Device = DeviceMobile
+	utils.Expect(t, "evening", SegmentByTimeOfDay(c))
+	utils.Expect(t, "weekend", SegmentByWeekend(c))
+	utils.Expect(t, "mobile", SegmentByDevice(c))
+	utils.Expect(t, "mobile|evening", CombineSegmenters(SegmentByDevice, SegmentByTimeOfDay)(c))
+	utils.Expect(t, "", CombineSegmenters(SegmentByLocale, SegmentByDevice)(c))
+	utils.Expect(t, "", SegmentByTimeOfDay(nil))
+
+	c.Time = time.Date(2014, 3, 3, 5, 59, 0, 0, time.UTC)
+	utils.Expect(t, "night weekday", SegmentByTimeOfDay(c)+" "+SegmentByWeekend(c))
+}
+
+func TestContextualRecommender(t *testing.T) {
+	morning := time.Date(2014, 3, 3, 8, 0, 0, 0, time.UTC)
+	evening := time.Date(2014, 3, 3, 20, 0, 0, 0, time.UTC)
+	var records []*Record
+	// product 1 is popular in the morning, product 2 overall
+	for u := 0; u < 3; u++ {
+		records = append(records, NewEvent(u, 1, EventView, morning))
+	}
+	for u := 0; u < 4; u++ {
+		records = append(records, NewEvent(u, 2, EventPurchase, evening))
+	}
+	records = append(records, NewEvent(9, 3, EventView, time.Time{}))
+
+	r := NewContextualRecommender(func() Recommender { return NewInmemRecommender() }, SegmentByTimeOfDay, 4)
+	if err := r.Fit(records); err != nil {
+		t.Fatal(err)
+	}
+	// the morning segment has 3 records, too few for its own model
+	utils.Expect(t, "[evening]", r.Segments())
+
+	utils.Expect(t, "true true", fmt.Sprint(r.GetSegmentModel(&Context{Time: morning}) == nil,
+		r.GetSegmentModel(&Context{Time: evening}) != nil))
+
+	utils.Expect(t, "2 ", fmtIds(r.Recommend(5, &Context{Time: evening}, 1)))
+	// the evening model knows only product 2, the rest comes from the global model
+	utils.Expect(t, "2 1 3 ", fmtIds(r.Recommend(5, &Context{Time: evening}, 3)))
+	utils.Expect(t, "2 1 3 ", fmtIds(r.Recommend(5, &Context{Time: morning}, 3)))
+	utils.Expect(t, "3 ", fmtIds(r.Recommend(0, nil, 3)))
+}
+
+func fmtIds(products []*ScoredProduct) string {
+	s := ""
+	for _, p := range products {
+		s += fmt.Sprint(p.ProductId, " ")
+	}
+	return s
+}
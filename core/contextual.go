@@ -0,0 +1,142 @@
+// Copyright (c) 2014 Feng Wang <wffrank1987@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language
+
+package core
+
+import (
+	"sort"
+)
+
+// A contextual pre-filtering recommender. The records are split into
+// context segments and one model is trained on each segment. The
+// recommendations for a context come from the model of its segment,
+// and the global model trained on all of the records is used when the
+// segment is too sparse to have its own model.
+type ContextualRecommender struct {
+	// Create an untrained model
+	factory func() Recommender
+
+	segmenter ContextSegmenter
+
+	// The minimum number of records to train a segment model
+	minRecords int
+
+	global Recommender
+
+	// segment name -> model
+	segments map[string]Recommender
+}
+
+// Create a contextual recommender training the models created by the factory
+// on the segments of the segmenter with at least minRecords records
+func NewContextualRecommender(factory func() Recommender, segmenter ContextSegmenter, minRecords int) *ContextualRecommender {
+	recommender := new(ContextualRecommender)
+	recommender.factory = factory
+	recommender.segmenter = segmenter
+	recommender.minRecords = minRecords
+	return recommender
+}
+
+func (r *ContextualRecommender) Fit(records []*Record) error {
+	if len(records) == 0 {
+		return ErrorEmptyRecords
+	}
+	r.global = r.factory()
+	if err := r.global.Fit(records); err != nil {
+		return err
+	}
+
+	groups := make(map[string][]*Record)
+	for _, record := range records {
+		if name := r.segmenter(RecordContext(record)); name != "" {
+			groups[name] = append(groups[name], record)
+		}
+	}
+
+	r.segments = make(map[string]Recommender)
+	for name, group := range groups {
+		if len(group) < r.minRecords {
+			continue
+		}
+		model := r.factory()
+		if err := model.Fit(group); err != nil {
+			return err
+		}
+		r.segments[name] = model
+	}
+	return nil
+}
+
+// Recommend with the model of the context segment, and fill up the
+// list from the global model if the segment model returns less than n
+func (r *ContextualRecommender) Recommend(userId int, context *Context, n int) []*ScoredProduct {
+	if r.global == nil {
+		return nil
+	}
+	model := r.GetSegmentModel(context)
+	if model == nil {
+		return r.global.Recommend(userId, context, n)
+	}
+	result := model.Recommend(userId, context, n)
+	if len(result) >= n {
+		return result
+	}
+	seen := make(map[int]bool)
+	for _, p := range result {
+		seen[p.ProductId] = true
+	}
+	for _, p := range r.global.Recommend(userId, context, n+len(result)) {
+		if len(result) >= n {
+			break
+		}
+		if !seen[p.ProductId] {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+// Predict with the model of the context segment if it is a Predictor,
+// otherwise with the global model. It returns 0 if neither is a Predictor.
+func (r *ContextualRecommender) PredictInContext(userId, productId int, context *Context) float64 {
+	if p, ok := r.GetSegmentModel(context).(Predictor); ok {
+		return p.Predict(userId, productId)
+	}
+	if p, ok := r.global.(Predictor); ok {
+		return p.Predict(userId, productId)
+	}
+	return 0
+}
+
+// Get the model of the segment of the context, nil if the segment has no model
+func (r *ContextualRecommender) GetSegmentModel(context *Context) Recommender {
+	if context == nil {
+		return nil
+	}
+	return r.segments[r.segmenter(context)]
+}
+
+// Get the global model
+func (r *ContextualRecommender) GetGlobalModel() Recommender {
+	return r.global
+}
+
+// Get the names of the segments which have their own models in ascending order
+func (r *ContextualRecommender) Segments() []string {
+	names := make([]string, 0, len(r.segments))
+	for name := range r.segments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,60 @@
+// Copyright (c) 2014 Feng Wang <wffrank1987@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language
+
+package core
+
+import (
+	"fmt"
+)
+
+const (
+	// No record is provided for training
+	errorEmptyRecords = iota
+	// The identification of user or product is invalid
+	errorIllegalId
+	// The category does not exist in the taxonomy
+	errorUnknownCategory
+	// The category already exists in the taxonomy
+	errorDuplicateCategory
+)
+
+type error_ int
+
+func (e error_) Error() string {
+	switch e {
+	case errorEmptyRecords:
+		return "No record to fit"
+	case errorIllegalId:
+		return "Illegal user or product id"
+	case errorUnknownCategory:
+		return "Unknown category"
+	case errorDuplicateCategory:
+		return "Duplicate category"
+	}
+	return fmt.Sprintf("Unknown error code %d", e)
+}
+
+func (e error_) String() string {
+	return e.Error()
+}
+
+var (
+	// No record is provided for training.
+	ErrorEmptyRecords error_ = error_(errorEmptyRecords)
+	// The identification of user or product is invalid.
+	ErrorIllegalId error_ = error_(errorIllegalId)
+	// The category does not exist in the taxonomy.
+	ErrorUnknownCategory error_ = error_(errorUnknownCategory)
+	// The category already exists in the taxonomy.
+	ErrorDuplicateCategory error_ = error_(errorDuplicateCategory)
+)
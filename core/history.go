@@ -0,0 +1,193 @@
+// Copyright (c) 2014 Feng Wang <wffrank1987@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language
+
+package core
+
+import (
+	"sort"
+	"time"
+)
+
+// The interactions of one user
+type UserHistory struct {
+	UserId int
+
+	// product id -> the latest time of any interaction
+	Seen map[int]time.Time
+
+	// product id -> the latest time of purchase
+	Purchased map[int]time.Time
+
+	// product id -> the latest rating
+	Rated map[int]float64
+
+	// All of the records of the user ordered by time
+	Records []*Record
+}
+
+func newUserHistory(userId int) *UserHistory {
+	h := new(UserHistory)
+	h.UserId = userId
+	h.Seen = make(map[int]time.Time)
+	h.Purchased = make(map[int]time.Time)
+	h.Rated = make(map[int]float64)
+	return h
+}
+
+func (h *UserHistory) add(r *Record) {
+	// keep the records ordered by time
+	i := sort.Search(len(h.Records), func(i int) bool {
+		return r.Timestamp.Before(h.Records[i].Timestamp)
+	})
+	h.Records = append(h.Records, nil)
+	copy(h.Records[i+1:], h.Records[i:])
+	h.Records[i] = r
+
+	if t, ok := h.Seen[r.ProductId]; !ok || t.Before(r.Timestamp) {
+		h.Seen[r.ProductId] = r.Timestamp
+	}
+	switch r.Event {
+	case EventPurchase:
+		if t, ok := h.Purchased[r.ProductId]; !ok || t.Before(r.Timestamp) {
+			h.Purchased[r.ProductId] = r.Timestamp
+		}
+	case EventRating:
+		// the records after r have been applied if r is out of order
+		if i == len(h.Records)-1 || !h.ratedAfter(r.ProductId, i) {
+			h.Rated[r.ProductId] = r.Value
+		}
+	}
+}
+
+func (h *UserHistory) ratedAfter(productId, i int) bool {
+	for _, r := range h.Records[i+1:] {
+		if r.ProductId == productId && r.Event == EventRating {
+			return true
+		}
+	}
+	return false
+}
+
+// Get the number of interactions of the user
+func (h *UserHistory) NumInteractions() int {
+	return len(h.Records)
+}
+
+// Get the ids of the products the user interacted with in ascending order
+func (h *UserHistory) SeenProducts() []int {
+	return sortedKeys(h.Seen)
+}
+
+// Get the ids of the products the user purchased in ascending order
+func (h *UserHistory) PurchasedProducts() []int {
+	return sortedKeys(h.Purchased)
+}
+
+// Get the n most recently interacted products, the latest first
+func (h *UserHistory) RecentProducts(n int) []int {
+	var ids []int
+	visited := make(map[int]bool)
+	for i := len(h.Records) - 1; i >= 0 && len(ids) < n; i-- {
+		id := h.Records[i].ProductId
+		if !visited[id] {
+			visited[id] = true
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// The per-user index of the interaction histories
+type HistoryIndex struct {
+	histories map[int]*UserHistory
+}
+
+func NewHistoryIndex() *HistoryIndex {
+	index := new(HistoryIndex)
+	index.histories = make(map[int]*UserHistory)
+	return index
+}
+
+// Build the history index from the records
+func BuildHistoryIndex(records []*Record) *HistoryIndex {
+	index := NewHistoryIndex()
+	for _, r := range records {
+		index.Add(r)
+	}
+	return index
+}
+
+// Add a record into the index
+func (index *HistoryIndex) Add(r *Record) {
+	h, ok := index.histories[r.UserId]
+	if !ok {
+		h = newUserHistory(r.UserId)
+		index.histories[r.UserId] = h
+	}
+	h.add(r)
+}
+
+// Get the number of users in the index
+func (index *HistoryIndex) NumUsers() int {
+	return len(index.histories)
+}
+
+// Get the ids of the users in ascending order
+func (index *HistoryIndex) Users() []int {
+	ids := make([]int, 0, len(index.histories))
+	for id := range index.histories {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// Get the history of the user, nil if the user has no interaction
+func (index *HistoryIndex) GetHistory(userId int) *UserHistory {
+	return index.histories[userId]
+}
+
+// Whether the user interacted with the product
+func (index *HistoryIndex) HasSeen(userId, productId int) bool {
+	h, ok := index.histories[userId]
+	if !ok {
+		return false
+	}
+	_, ok = h.Seen[productId]
+	return ok
+}
+
+// Whether the user purchased the product
+func (index *HistoryIndex) HasPurchased(userId, productId int) bool {
+	h, ok := index.histories[userId]
+	if !ok {
+		return false
+	}
+	_, ok = h.Purchased[productId]
+	return ok
+}
+
+// Whether the user has less than minInteractions interactions
+func (index *HistoryIndex) IsColdStart(userId, minInteractions int) bool {
+	h, ok := index.histories[userId]
+	return !ok || h.NumInteractions() < minInteractions
+}
+
+func sortedKeys(m map[int]time.Time) []int {
+	ids := make([]int, 0, len(m))
+	for id := range m {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
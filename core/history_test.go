@@ -0,0 +1,60 @@
+// Copyright (c) 2014 Feng Wang <wffrank1987@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language
+
+package core
+
+import (
+	"fmt"
+	"github.com/numb3r3/gorec/utils"
+	"testing"
+	"time"
+)
+
+func TestHistoryIndex(t *testing.T) {
+	t0 := time.Date(2014, 1, 1, 0, 0, 0, 0, time.UTC)
+	index := BuildHistoryIndex([]*Record{
+		NewEvent(0, 3, EventView, t0),
+		NewRating(0, 5, 2, t0.Add(2*time.Hour)),
+		NewEvent(0, 4, EventPurchase, t0.Add(3*time.Hour)),
+		// out of order, the later rating of product 5 must win
+		NewRating(0, 5, 4, t0.Add(time.Hour)),
+		NewEvent(1, 3, EventClick, t0),
+	})
+	h := index.GetHistory(0)
+	utils.Expect(t, "4", h.NumInteractions())
+	utils.Expect(t, "[3 4 5]", h.SeenProducts())
+	utils.Expect(t, "[4]", h.PurchasedProducts())
+	utils.Expect(t, "2", h.Rated[5])
+	utils.Expect(t, "[4 5]", h.RecentProducts(2))
+
+	utils.Expect(t, "[0 1]", index.Users())
+	utils.Expect(t, "true false", fmt.Sprint(index.HasSeen(1, 3), index.HasSeen(1, 4)))
+	utils.Expect(t, "true false", fmt.Sprint(index.HasPurchased(0, 4), index.HasPurchased(0, 3)))
+	utils.Expect(t, "true false true", fmt.Sprint(index.IsColdStart(1, 2), index.IsColdStart(0, 2), index.IsColdStart(7, 1)))
+	utils.Expect(t, "true", index.GetHistory(7) == nil)
+}
+
+func TestUserFeatures(t *testing.T) {
+	u := NewUser(1, "alice")
+	u.Segment = "student"
+	u.Attributes["gender"] = "female"
+	now := time.Date(2014, 3, 1, 0, 0, 0, 0, time.UTC)
+	utils.Expect(t, "-1", u.TenureDays(now))
+
+	u.SignupDate = now.AddDate(0, 0, -10)
+	utils.Expect(t, "10", u.TenureDays(now))
+	utils.Expect(t, "map[gender=female:1 segment=student:1 tenure_days:10]", u.NamedFeatures(now))
+
+	instance := u.ToInstance(now)
+	utils.Expect(t, "alice 3", fmt.Sprint(instance.Name, " ", len(instance.NamedFeatures)))
+}
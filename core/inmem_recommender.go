@@ -0,0 +1,91 @@
+// Copyright (c) 2014 Feng Wang <wffrank1987@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language
+
+package core
+
+// A reference recommender keeping all of the records in memory.
+// It recommends the most popular products (by the sum of the
+// weighted interactions) which the user has not interacted with.
+type InmemRecommender struct {
+	records []*Record
+
+	// user id -> product id -> aggregated value
+	histories map[int]map[int]float64
+
+	// product id -> aggregated value over all of the users
+	popularity map[int]float64
+
+	// The weights of the events, nil for the default weights
+	weights EventWeights
+
+	fitted bool
+}
+
+func NewInmemRecommender() *InmemRecommender {
+	recommender := new(InmemRecommender)
+	return recommender
+}
+
+// Set the weights of the events used to aggregate the records
+func (r *InmemRecommender) SetEventWeights(weights EventWeights) {
+	r.weights = weights
+}
+
+// Fit on the records, the fitted state is kept if an error is returned
+func (r *InmemRecommender) Fit(records []*Record) error {
+	if len(records) == 0 {
+		return ErrorEmptyRecords
+	}
+	if err := checkIds(records); err != nil {
+		return err
+	}
+	histories := make(map[int]map[int]float64)
+	popularity := make(map[int]float64)
+	for _, record := range records {
+		history, ok := histories[record.UserId]
+		if !ok {
+			history = make(map[int]float64)
+			histories[record.UserId] = history
+		}
+		w := record.Weight(r.weights)
+		history[record.ProductId] += w
+		popularity[record.ProductId] += w
+	}
+	r.records, r.histories, r.popularity = records, histories, popularity
+	r.fitted = true
+	return nil
+}
+
+func (r *InmemRecommender) Recommend(userId int, context *Context, n int) []*ScoredProduct {
+	if !r.fitted {
+		return nil
+	}
+	history := r.histories[userId]
+	scores := make(map[int]float64)
+	for id, score := range r.popularity {
+		if _, seen := history[id]; !seen {
+			scores[id] = score
+		}
+	}
+	return TopN(scores, n)
+}
+
+// Get the aggregated interaction value of the user on the product
+func (r *InmemRecommender) Predict(userId, productId int) float64 {
+	return r.histories[userId][productId]
+}
+
+// Get all of the records the recommender is fitted on
+func (r *InmemRecommender) Records() []*Record {
+	return r.records
+}
@@ -12,3 +12,93 @@
 //    See the License for the specific language
 
 package core
+
+import (
+	"time"
+)
+
+// A product (item) which can be recommended
+type Product struct {
+	// The internal identification of the product, it is also the
+	// column index of the product in the user-product matrix
+	Id int
+
+	// The external name of the product
+	// Can be empty
+	Name string
+
+	// The title and description text of the product
+	Title       string
+	Description string
+
+	// The name of the category in the taxonomy the product belongs to
+	// Can be empty
+	Category string
+
+	// The free-form tags of the product
+	Tags []string
+
+	// The typed attributes of the product, the values are one of
+	// string, float64, bool and time.Time
+	Attributes map[string]interface{}
+}
+
+func NewProduct(id int, name string) *Product {
+	return &Product{Id: id, Name: name, Attributes: make(map[string]interface{})}
+}
+
+// Set the attribute value
+func (p *Product) SetAttribute(name string, value interface{}) {
+	if p.Attributes == nil {
+		p.Attributes = make(map[string]interface{})
+	}
+	p.Attributes[name] = value
+}
+
+// Get the attribute value, nil if the attribute does not exist
+func (p *Product) GetAttribute(name string) interface{} {
+	return p.Attributes[name]
+}
+
+// Get the string attribute
+func (p *Product) GetStringAttribute(name string) (string, bool) {
+	v, ok := p.Attributes[name].(string)
+	return v, ok
+}
+
+// Get the numeric attribute, the integers are converted to float64
+func (p *Product) GetFloatAttribute(name string) (float64, bool) {
+	switch v := p.Attributes[name].(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	}
+	return 0, false
+}
+
+// Get the boolean attribute
+func (p *Product) GetBoolAttribute(name string) (bool, bool) {
+	v, ok := p.Attributes[name].(bool)
+	return v, ok
+}
+
+// Get the time attribute
+func (p *Product) GetTimeAttribute(name string) (time.Time, bool) {
+	v, ok := p.Attributes[name].(time.Time)
+	return v, ok
+}
+
+// Whether the product has the tag
+func (p *Product) HasTag(tag string) bool {
+	for _, t := range p.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,124 @@
+// Copyright (c) 2014 Feng Wang <wffrank1987@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language
+
+package core
+
+import (
+	"container/heap"
+	"sort"
+)
+
+// The interface shared by all of the recommending algorithms
+type Recommender interface {
+
+	// Train the model on a collection of interaction records
+	Fit(records []*Record) error
+
+	// Get the top-n scored products for the user under the context
+	// The context can be nil
+	Recommend(userId int, context *Context, n int) []*ScoredProduct
+}
+
+// The interface of the models which can predict the interaction
+// value (e.g. rating) between a user and a product
+type Predictor interface {
+
+	// Predict the value of the user on the product
+	Predict(userId, productId int) float64
+}
+
+// A product with its recommending score
+type ScoredProduct struct {
+	ProductId int
+
+	Score float64
+}
+
+// Sort the products by descending score, and ascending id for ties
+func SortScoredProducts(products []*ScoredProduct) {
+	sort.Sort(byScore(products))
+}
+
+// Get the top-n scored products from the product -> score map
+func TopN(scores map[int]float64, n int) []*ScoredProduct {
+	h := &scoredHeap{}
+	for id, score := range scores {
+		h.offer(&ScoredProduct{ProductId: id, Score: score}, n)
+	}
+	return h.sorted()
+}
+
+// Get the top-n scored products from the dense score array indexed by
+// product id, skipping the products in exclude (which can be nil)
+func TopNSlice(scores []float64, n int, exclude map[int]bool) []*ScoredProduct {
+	h := &scoredHeap{}
+	for id, score := range scores {
+		if exclude[id] {
+			continue
+		}
+		h.offer(&ScoredProduct{ProductId: id, Score: score}, n)
+	}
+	return h.sorted()
+}
+
+type byScore []*ScoredProduct
+
+func (s byScore) Len() int { return len(s) }
+
+func (s byScore) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+
+func (s byScore) Less(i, j int) bool { return better(s[i], s[j]) }
+
+// Whether a ranks before b
+func better(a, b *ScoredProduct) bool {
+	if a.Score != b.Score {
+		return a.Score > b.Score
+	}
+	return a.ProductId < b.ProductId
+}
+
+// A min-heap keeping the n best products seen so far
+type scoredHeap []*ScoredProduct
+
+func (h scoredHeap) Len() int { return len(h) }
+
+func (h scoredHeap) Less(i, j int) bool { return better(h[j], h[i]) }
+
+func (h scoredHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *scoredHeap) Push(x interface{}) { *h = append(*h, x.(*ScoredProduct)) }
+
+func (h *scoredHeap) Pop() interface{} {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+func (h *scoredHeap) offer(p *ScoredProduct, n int) {
+	if n <= 0 {
+		return
+	}
+	if h.Len() < n {
+		heap.Push(h, p)
+	} else if better(p, (*h)[0]) {
+		(*h)[0] = p
+		heap.Fix(h, 0)
+	}
+}
+
+func (h *scoredHeap) sorted() []*ScoredProduct {
+	result := []*ScoredProduct(*h)
+	SortScoredProducts(result)
+	return result
+}
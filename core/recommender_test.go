@@ -0,0 +1,63 @@
+// Copyright (c) 2014 Feng Wang <wffrank1987@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language
+
+package core
+
+import (
+	"github.com/numb3r3/gorec/utils"
+	"testing"
+)
+
+func TestTopN(t *testing.T) {
+	scores := map[int]float64{0: 0.5, 1: 2.0, 2: 1.0, 3: 2.0, 4: -1.0}
+	top := TopN(scores, 3)
+	utils.Expect(t, "3", len(top))
+	utils.Expect(t, "1", top[0].ProductId)
+	utils.Expect(t, "3", top[1].ProductId)
+	utils.Expect(t, "2", top[2].ProductId)
+
+	top = TopNSlice([]float64{0.5, 2.0, 1.0, 2.0}, 2, map[int]bool{1: true})
+	utils.Expect(t, "2", len(top))
+	utils.Expect(t, "3", top[0].ProductId)
+	utils.Expect(t, "2", top[1].ProductId)
+}
+
+func TestInmemRecommender(t *testing.T) {
+	records := []*Record{
+		&Record{UserId: 0, ProductId: 0, Value: 1},
+		&Record{UserId: 0, ProductId: 1, Value: 1},
+		&Record{UserId: 1, ProductId: 1, Value: 1},
+		&Record{UserId: 1, ProductId: 2, Value: 1},
+		&Record{UserId: 2, ProductId: 1, Value: 1},
+		&Record{UserId: 2, ProductId: 2, Value: 1},
+	}
+	var recommender Recommender = NewInmemRecommender()
+	if err := recommender.Fit(records); err != nil {
+		t.Fatal(err)
+	}
+
+	top := recommender.Recommend(0, nil, 10)
+	utils.Expect(t, "1", len(top))
+	utils.Expect(t, "2", top[0].ProductId)
+	utils.Expect(t, "2", top[0].Score)
+
+	utils.Expect(t, "No record to fit", recommender.Fit(nil))
+
+	// the illegal records leave the fitted model untouched
+	illegal := []*Record{&Record{UserId: 5, ProductId: 5, Value: 1}, &Record{UserId: -1, ProductId: 0, Value: 1}}
+	utils.Expect(t, ErrorIllegalId.Error(), recommender.Fit(illegal))
+	top = recommender.Recommend(0, nil, 10)
+	utils.Expect(t, "1", len(top))
+	utils.Expect(t, "2", top[0].ProductId)
+	utils.Expect(t, "6", len(recommender.(*InmemRecommender).Records()))
+}
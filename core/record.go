@@ -12,3 +12,302 @@
 //    See the License for the specific language
 
 package core
+
+import (
+	"github.com/numb3r3/gorec/data"
+	"github.com/numb3r3/gorec/math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The type of an interaction between a user and a product
+type EventType int
+
+const (
+	// An explicit rating, the value of the record is the rating
+	EventRating EventType = iota
+	// The user viewed the product
+	EventView
+	// The user clicked the product
+	EventClick
+	// The user added the product to the cart
+	EventAddToCart
+	// The user purchased the product
+	EventPurchase
+)
+
+func (e EventType) String() string {
+	switch e {
+	case EventRating:
+		return "rating"
+	case EventView:
+		return "view"
+	case EventClick:
+		return "click"
+	case EventAddToCart:
+		return "add-to-cart"
+	case EventPurchase:
+		return "purchase"
+	}
+	return "unknown"
+}
+
+// Whether the event is an explicit feedback
+func (e EventType) IsExplicit() bool {
+	return e == EventRating
+}
+
+// The weights of the implicit events used to aggregate records into
+// the interaction strength. The events without weight are ignored.
+type EventWeights map[EventType]float64
+
+// The default weights: a purchase is worth more than an add-to-cart,
+// which is worth more than a click, which is worth more than a view
+func DefaultEventWeights() EventWeights {
+	return EventWeights{
+		EventRating:    1,
+		EventView:      1,
+		EventClick:     2,
+		EventAddToCart: 4,
+		EventPurchase:  8,
+	}
+}
+
+// The weights used when none is given, it must not be modified
+var defaultEventWeights = DefaultEventWeights()
+
+// One interaction between a user and a product
+type Record struct {
+	// The identification of the user
+	UserId int
+
+	// The identification of the product
+	ProductId int
+
+	// The type of the interaction
+	Event EventType
+
+	// The rating for explicit feedback, or the number of times
+	// of the implicit event (zero is regarded as once)
+	Value float64
+
+	// When the interaction happened
+	// Can be the zero time if unknown
+	Timestamp time.Time
+
+	// The context in which the interaction happened
+	// Can be nil
+	Context *Context
+}
+
+// Create a record of explicit rating
+func NewRating(userId, productId int, rating float64, timestamp time.Time) *Record {
+	return &Record{UserId: userId, ProductId: productId, Event: EventRating, Value: rating, Timestamp: timestamp}
+}
+
+// Create a record of an implicit event happened once
+func NewEvent(userId, productId int, event EventType, timestamp time.Time) *Record {
+	return &Record{UserId: userId, ProductId: productId, Event: event, Value: 1, Timestamp: timestamp}
+}
+
+// Whether the record is an explicit feedback
+func (r *Record) IsExplicit() bool {
+	return r.Event.IsExplicit()
+}
+
+// Get the interaction strength of the record under the event weights.
+// The default weights are used if weights is nil.
+func (r *Record) Weight(weights EventWeights) float64 {
+	if weights == nil {
+		weights = defaultEventWeights
+	}
+	w := weights[r.Event]
+	if r.IsExplicit() {
+		return w * r.Value
+	}
+	if r.Value == 0 {
+		return w
+	}
+	return w * r.Value
+}
+
+// The prefixes of the one-hot named features of the user and the product
+const (
+	UserFeaturePrefix    = "user="
+	ProductFeaturePrefix = "product="
+)
+
+// Get the name of the one-hot feature of the user
+func UserFeature(userId int) string {
+	return UserFeaturePrefix + strconv.Itoa(userId)
+}
+
+// Get the name of the one-hot feature of the product
+func ProductFeature(productId int) string {
+	return ProductFeaturePrefix + strconv.Itoa(productId)
+}
+
+// Convert the record into an instance, the user and the product are the
+// one-hot named features "user=<id>" and "product=<id>" and the value is
+// the output value
+func (r *Record) ToInstance() *data.Instance {
+	return &data.Instance{
+		NamedFeatures: map[string]float64{
+			UserFeature(r.UserId):       1,
+			ProductFeature(r.ProductId): 1,
+		},
+		Output:      &data.InstanceOutput{Value: r.Value},
+		Attachement: r,
+	}
+}
+
+// Convert the instance made by Record.ToInstance back into a record,
+// nil if the instance has no user feature or product feature
+func RecordFromInstance(instance *data.Instance) *Record {
+	if r, ok := instance.Attachement.(*Record); ok {
+		return r
+	}
+	r := &Record{UserId: -1, ProductId: -1}
+	for name, value := range instance.NamedFeatures {
+		if value == 0 {
+			continue
+		}
+		if strings.HasPrefix(name, UserFeaturePrefix) {
+			if id, err := strconv.Atoi(name[len(UserFeaturePrefix):]); err == nil {
+				r.UserId = id
+			}
+		} else if strings.HasPrefix(name, ProductFeaturePrefix) {
+			if id, err := strconv.Atoi(name[len(ProductFeaturePrefix):]); err == nil {
+				r.ProductId = id
+			}
+		}
+	}
+	if r.UserId < 0 || r.ProductId < 0 {
+		return nil
+	}
+	if instance.Output != nil {
+		r.Value = instance.Output.Value
+	}
+	return r
+}
+
+// Build a finalized in-memory dataset of the records
+func RecordsDataset(records []*Record) data.Dataset {
+	dataset := data.NewInmemDataset()
+	for _, r := range records {
+		dataset.AddInstance(r.ToInstance())
+	}
+	dataset.Finalize()
+	return dataset
+}
+
+// Get all of the records in the dataset made by RecordsDataset
+func DatasetRecords(dataset data.Dataset) []*Record {
+	var records []*Record
+	it := dataset.CreateIterator()
+	for it.Start(); !it.End(); it.Next() {
+		if r := RecordFromInstance(it.GetInstance()); r != nil {
+			records = append(records, r)
+		}
+	}
+	return records
+}
+
+// Get the minimum dimension of the user-product matrix holding the records
+func RecordsDimension(records []*Record) (rows, cols int) {
+	for _, r := range records {
+		if r.UserId >= rows {
+			rows = r.UserId + 1
+		}
+		if r.ProductId >= cols {
+			cols = r.ProductId + 1
+		}
+	}
+	return
+}
+
+// Sort the records by ascending timestamp (stable for ties)
+func SortRecordsByTime(records []*Record) {
+	sort.Stable(byTimestamp(records))
+}
+
+// Build the user x product rating matrix from the explicit records.
+// The latest rating wins if a user rated a product several times.
+// The dimension is inferred from the records if rows or cols is not positive.
+// ErrorIllegalId is returned if any record has a negative id.
+func RatingMatrix(records []*Record, rows, cols int) (*math.SparseMatrix, error) {
+	if err := checkIds(records); err != nil {
+		return nil, err
+	}
+	rows, cols = matrixDimension(records, rows, cols)
+	R := math.ZerosSparse(rows, cols)
+	latest := make(map[[2]int]time.Time)
+	for _, r := range records {
+		if !r.IsExplicit() || r.UserId >= rows || r.ProductId >= cols {
+			continue
+		}
+		key := [2]int{r.UserId, r.ProductId}
+		if t, ok := latest[key]; ok && r.Timestamp.Before(t) {
+			continue
+		}
+		latest[key] = r.Timestamp
+		R.Set(r.UserId, r.ProductId, r.Value)
+	}
+	return R, nil
+}
+
+// Build the user x product interaction matrix by summing up the
+// weighted records. The default weights are used if weights is nil.
+// The dimension is inferred from the records if rows or cols is not positive.
+// ErrorIllegalId is returned if any record has a negative id.
+func InteractionMatrix(records []*Record, weights EventWeights, rows, cols int) (*math.SparseMatrix, error) {
+	if err := checkIds(records); err != nil {
+		return nil, err
+	}
+	rows, cols = matrixDimension(records, rows, cols)
+	R := math.ZerosSparse(rows, cols)
+	for _, r := range records {
+		if r.UserId >= rows || r.ProductId >= cols {
+			continue
+		}
+		w := r.Weight(weights)
+		if w == 0 {
+			continue
+		}
+		R.Set(r.UserId, r.ProductId, R.Get(r.UserId, r.ProductId)+w)
+	}
+	return R, nil
+}
+
+// Check that no record has a negative user or product id
+func checkIds(records []*Record) error {
+	for _, r := range records {
+		if r.UserId < 0 || r.ProductId < 0 {
+			return ErrorIllegalId
+		}
+	}
+	return nil
+}
+
+func matrixDimension(records []*Record, rows, cols int) (int, int) {
+	if rows <= 0 || cols <= 0 {
+		r, c := RecordsDimension(records)
+		if rows <= 0 {
+			rows = r
+		}
+		if cols <= 0 {
+			cols = c
+		}
+	}
+	return rows, cols
+}
+
+type byTimestamp []*Record
+
+func (s byTimestamp) Len() int { return len(s) }
+
+func (s byTimestamp) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+
+func (s byTimestamp) Less(i, j int) bool { return s[i].Timestamp.Before(s[j].Timestamp) }
@@ -0,0 +1,79 @@
+// Copyright (c) 2014 Feng Wang <wffrank1987@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language
+
+package core
+
+import (
+	"fmt"
+	"github.com/numb3r3/gorec/utils"
+	"testing"
+	"time"
+)
+
+func TestRecordWeight(t *testing.T) {
+	now := time.Now()
+	utils.Expect(t, "8", NewEvent(0, 0, EventPurchase, now).Weight(nil))
+	utils.Expect(t, "4", NewRating(0, 0, 4, now).Weight(nil))
+	utils.Expect(t, "3", NewEvent(0, 0, EventClick, now).Weight(EventWeights{EventClick: 3}))
+	utils.Expect(t, "0", NewEvent(0, 0, EventView, now).Weight(EventWeights{EventClick: 3}))
+}
+
+func TestRatingMatrix(t *testing.T) {
+	now := time.Now()
+	records := []*Record{
+		NewRating(0, 1, 3, now.Add(time.Hour)),
+		NewRating(0, 1, 5, now),
+		NewEvent(1, 0, EventView, now),
+		NewRating(2, 0, 4, now),
+	}
+	R, err := RatingMatrix(records, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the latest rating wins and the implicit events are skipped
+	utils.Expect(t, "[0 3 0 0 4 0]", R.Array())
+
+	records = append(records, NewRating(-1, 0, 5, now))
+	_, err = RatingMatrix(records, 0, 0)
+	utils.Expect(t, ErrorIllegalId.Error(), err)
+}
+
+func TestInteractionMatrix(t *testing.T) {
+	now := time.Now()
+	records := []*Record{
+		NewEvent(0, 1, EventView, now),
+		NewEvent(0, 1, EventPurchase, now),
+		NewEvent(1, 0, EventClick, now),
+	}
+	R, err := InteractionMatrix(records, nil, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	utils.Expect(t, "[0 9 2 0]", R.Array())
+
+	_, err = InteractionMatrix([]*Record{NewEvent(0, -2, EventView, now)}, nil, 0, 0)
+	utils.Expect(t, ErrorIllegalId.Error(), err)
+}
+
+func TestRecordInstance(t *testing.T) {
+	instance := NewRating(3, 7, 4.5, time.Time{}).ToInstance()
+	utils.Expect(t, "map[product=7:1 user=3:1]", instance.NamedFeatures)
+
+	// without the attached record the ids are parsed from the feature names
+	instance.Attachement = nil
+	r := RecordFromInstance(instance)
+	utils.Expect(t, "3 7 4.5", fmt.Sprint(r.UserId, " ", r.ProductId, " ", r.Value))
+
+	delete(instance.NamedFeatures, ProductFeature(7))
+	utils.Expect(t, "true", RecordFromInstance(instance) == nil)
+}
@@ -12,3 +12,72 @@
 //    See the License for the specific language
 
 package core
+
+import (
+	"github.com/numb3r3/gorec/data"
+	"time"
+)
+
+// A user of the recommending service
+type User struct {
+	// The internal identification of the user, it is also the
+	// row index of the user in the user-product matrix
+	Id int
+
+	// The external name of the user
+	// Can be empty
+	Name string
+
+	// The marketing segment of the user (e.g. "student")
+	Segment string
+
+	// The locale of the user (e.g. "en_US")
+	Locale string
+
+	// When the user signed up
+	// Can be the zero time if unknown
+	SignupDate time.Time
+
+	// Other demographic attributes (e.g. "gender" -> "female")
+	Attributes map[string]string
+}
+
+func NewUser(id int, name string) *User {
+	return &User{Id: id, Name: name, Attributes: make(map[string]string)}
+}
+
+// Get the number of days since the user signed up, -1 if unknown
+func (u *User) TenureDays(now time.Time) int {
+	if u.SignupDate.IsZero() || now.Before(u.SignupDate) {
+		return -1
+	}
+	return int(now.Sub(u.SignupDate).Hours() / 24)
+}
+
+// Get the user attributes as one-hot named features, e.g. "segment=student".
+// The tenure in days is added as "tenure_days" if it is known at now.
+func (u *User) NamedFeatures(now time.Time) map[string]float64 {
+	features := make(map[string]float64)
+	if u.Segment != "" {
+		features["segment="+u.Segment] = 1
+	}
+	if u.Locale != "" {
+		features["locale="+u.Locale] = 1
+	}
+	for k, v := range u.Attributes {
+		features[k+"="+v] = 1
+	}
+	if days := u.TenureDays(now); days >= 0 {
+		features["tenure_days"] = float64(days)
+	}
+	return features
+}
+
+// Convert the user into an unlabeled instance with the named features
+func (u *User) ToInstance(now time.Time) *data.Instance {
+	return &data.Instance{
+		NamedFeatures: u.NamedFeatures(now),
+		Name:          u.Name,
+		Attachement:   u,
+	}
+}
@@ -36,38 +36,24 @@ type Dataset interface {
 	GetLabelDictionary() *utils.Dictionary
 }
 
-// The structure of dataset options
-type DatasetOptions struct {
-
-	// Wether the feature is stored in sparse vector
-	FeatureIsSparse bool
-	
-	// The dimenion of the feature vector
-	FeatureDimension int
-	
-
-	// Wether it is a supervised learning problem
-	IsSupervisedLearning bool
-
-	// The number of target labels
-	NumLabels int
-	
-	// Other options
-	Options interface()
-}
-
 func ConvertNamedFeatures(instance *Instance, dict *utils.Dictionary) {
 	if instance.Features != nil {
 		return
 	}
 
-	instance.Features = NewSparseVector()
+	dimension := dict.MaxId()
+	if dimension < 1 {
+		dimension = 1
+	}
+	instance.Features = make([]float64, dimension)
 	
 	// The first element value is asways 1.0
-	instance.Features.Set(0, 1.0)
+	instance.Features[0] = 1.0
 
 	for k, v := range instance.NamedFeatures {
-		id : = dict.GetIdFromName(k)
-		instance.Features.Set(id, v)
+		id := dict.GetIdFromName(k)
+		if id > 0 {
+			instance.Features[id] = v
+		}
 	}
 }
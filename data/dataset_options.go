@@ -13,16 +13,23 @@
 
 package data
 
+// The structure of dataset options
 type DatasetOptions struct {
+
+	// Wether the feature is stored in sparse vector
 	FeatureIsSparse bool
 	
+	// The dimenion of the feature vector
 	FeatureDimension int
 	
-	IsSupervisedLearning int
 
+	// Wether it is a supervised learning problem
+	IsSupervisedLearning bool
+
+	// The number of target labels
 	NumLabels int
 	
-
+	// Other options
 	Options interface{}
 }
 
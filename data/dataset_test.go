@@ -0,0 +1,49 @@
+// Copyright (c) 2014 Feng Wang <wffrank1987@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language
+
+package data
+
+import (
+	"fmt"
+	"github.com/numb3r3/gorec/utils"
+	"testing"
+)
+
+func TestInmemDataset(t *testing.T) {
+	dataset := NewInmemDataset()
+	for i := 0; i < 3; i++ {
+		dataset.AddInstance(&Instance{Features: []float64{float64(i)}})
+	}
+	dataset.Finalize()
+	utils.Expect(t, "3", fmt.Sprint(dataset.NumInstance()))
+
+	values := ""
+	it := dataset.CreateIterator()
+	for it.Start(); !it.End(); it.Next() {
+		values += fmt.Sprint(it.GetInstance().Features[0], " ")
+	}
+	utils.Expect(t, "0 1 2 ", values)
+}
+
+func TestConvertNamedFeatures(t *testing.T) {
+	dict := utils.NewDictionary(1)
+	dict.AddName("a")
+	dict.AddName("b")
+	dict.AddName("a")
+	utils.Expect(t, "3 2", fmt.Sprint(dict.MaxId(), dict.Size()))
+
+	instance := &Instance{NamedFeatures: map[string]float64{"b": 2, "unknown": 3}}
+	ConvertNamedFeatures(instance, dict)
+	// the first element is the constant 1.0, unknown names are dropped
+	utils.Expect(t, "[1 0 2]", fmt.Sprint(instance.Features))
+}
@@ -58,7 +58,9 @@ func (dataset *inmemDataset) GetOptions() DatasetOptions {
 }
 
 func (dataset *inmemDataset) AddInstance(instance *Instance) bool {
-	
+	dataset.CheckFinalized(false)
+	dataset.instances = append(dataset.instances, instance)
+	return true
 }
 
 func (dataset *inmemDataset) Finalize() {
@@ -18,17 +18,17 @@ import (
 )
 
 type inmemDatasetIterator struct {
-	dateset *inmemDataset
+	dataset *inmemDataset
 	currIndex int
 }
 
-fucn (it *inmemDatasetIterator) Start() {
+func (it *inmemDatasetIterator) Start() {
 	it.dataset.CheckFinalized(true)
 	it.currIndex = 0
 }
 
 func (it *inmemDatasetIterator) End() bool {
-	it.dateset.CheckFinalized(true)
+	it.dataset.CheckFinalized(true)
 	if it.currIndex >= len(it.dataset.instances) {
 		return true
 	}
@@ -36,14 +36,14 @@ func (it *inmemDatasetIterator) End() bool {
 }
 
 func (it *inmemDatasetIterator) Next() {
-	it.dateset.CheckFinalized(true)
+	it.dataset.CheckFinalized(true)
 	if !it.End() {
 		it.currIndex++
 	}
 }
 
 func (it *inmemDatasetIterator) Skip(n int) {
-	it.dateset.CheckFinalized(true)
+	it.dataset.CheckFinalized(true)
 	if n < 0 {
 		log.Fatal("Skip step must be non-negative.")
 	}
@@ -51,9 +51,9 @@ func (it *inmemDatasetIterator) Skip(n int) {
 }
 
 func (it *inmemDatasetIterator) GetInstance() *Instance {
-	it.dateset.CheckFinalized(true)
+	it.dataset.CheckFinalized(true)
 	if it.End() {
 		return nil
 	}
-	return it.dateset.instances[it.currIndex]
+	return it.dataset.instances[it.currIndex]
 }
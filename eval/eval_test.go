@@ -0,0 +1,111 @@
+// Copyright (c) 2014 Feng Wang <wffrank1987@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language
+
+package eval
+
+import (
+	"fmt"
+	"github.com/numb3r3/gorec/core"
+	"github.com/numb3r3/gorec/utils"
+	"testing"
+	"time"
+)
+
+func TestRankingMetrics(t *testing.T) {
+	ranked := []int{5, 1, 7, 2}
+	relevant := map[int]bool{1: true, 2: true, 3: true}
+	utils.ExpectNear(t, 0.5, Precision(ranked, relevant, 4), 1e-9)
+	utils.ExpectNear(t, 2.0/3, Recall(ranked, relevant, 4), 1e-9)
+	utils.ExpectNear(t, 1, HitRate(ranked, relevant, 2), 1e-9)
+	utils.ExpectNear(t, 0, HitRate(ranked, relevant, 1), 1e-9)
+	utils.ExpectNear(t, 0.5, ReciprocalRank(ranked, relevant, 4), 1e-9)
+	// (1/2 + 2/4) / 3
+	utils.ExpectNear(t, 1.0/3, AveragePrecision(ranked, relevant, 4), 1e-9)
+	// (1/log2(3) + 1/log2(5)) / (1 + 1/log2(3) + 1/log2(4))
+	utils.ExpectNear(t, 0.4982, NDCG(ranked, relevant, 4), 1e-4)
+	// 10 candidates, 7 irrelevant: 1 beats 6, 2 beats 5, 3 ties with 5
+	utils.ExpectNear(t, (6+5+2.5)/21, AUC(ranked, relevant, 10), 1e-9)
+}
+
+func TestEvaluator(t *testing.T) {
+	now := time.Now()
+	var train []*core.Record
+	// product 0 is the most popular, then 1, 2 and 3
+	for u := 0; u < 4; u++ {
+		for p := 0; p <= 3-u; p++ {
+			train = append(train, core.NewEvent(u+10, p, core.EventView, now))
+		}
+	}
+	test := []*core.Record{
+		core.NewEvent(0, 0, core.EventView, now),
+		core.NewEvent(1, 3, core.EventView, now),
+		core.NewRating(2, 1, 2, now),
+	}
+	recommender := core.NewInmemRecommender()
+	recommender.Fit(train)
+
+	options := DefaultEvaluatorOptions()
+	options.K = 2
+	report := NewEvaluator(options).Evaluate(recommender, core.RecordsDataset(test))
+	// user 2 rated below the threshold has no relevant product
+	utils.Expect(t, "2", fmt.Sprint(report.NumUsers))
+	utils.ExpectNear(t, 0.5, report.HitRate, 1e-9)
+	utils.ExpectNear(t, 0.25, report.Precision, 1e-9)
+	utils.ExpectNear(t, 0.5, report.MRR, 1e-9)
+	utils.Expect(t, "1", fmt.Sprint(report.Users[0].Hit))
+
+	// the full ranking of the 4 products without cutoff
+	options.K = 0
+	report = NewEvaluator(options).Evaluate(recommender, core.RecordsDataset(test))
+	utils.ExpectNear(t, 1, report.HitRate, 1e-9)
+	utils.ExpectNear(t, 1, report.Recall, 1e-9)
+	utils.ExpectNear(t, 0.25, report.Precision, 1e-9)
+	utils.ExpectNear(t, (1+0.25)/2, report.MRR, 1e-9)
+}
+
+// The recommender returning the products in id order
+type fixedRecommender struct{}
+
+func (r fixedRecommender) Fit(records []*core.Record) error {
+	return nil
+}
+
+func (r fixedRecommender) Recommend(userId int, context *core.Context, n int) []*core.ScoredProduct {
+	products := make([]*core.ScoredProduct, n)
+	for i := range products {
+		products[i] = &core.ScoredProduct{ProductId: i, Score: float64(n - i)}
+	}
+	return products
+}
+
+func TestEvaluatorHistory(t *testing.T) {
+	now := time.Now()
+	options := DefaultEvaluatorOptions()
+	options.K = 0
+	options.NumProducts = 5
+	options.History = core.BuildHistoryIndex([]*core.Record{
+		core.NewEvent(0, 0, core.EventView, now),
+		core.NewEvent(0, 1, core.EventView, now),
+		core.NewEvent(0, 2, core.EventView, now),
+	})
+	test := []*core.Record{core.NewEvent(0, 3, core.EventView, now)}
+	// the seen products 0, 1 and 2 ranked above 3 are not candidates,
+	// leaving 3 above the only candidate 4
+	report := NewEvaluator(options).EvaluateRecords(fixedRecommender{}, test)
+	utils.ExpectNear(t, 1, report.AUC, 1e-9)
+
+	// without the history, 3 is below 3 of the 4 irrelevant products
+	options.History = nil
+	report = NewEvaluator(options).EvaluateRecords(fixedRecommender{}, test)
+	utils.ExpectNear(t, 0.25, report.AUC, 1e-9)
+}
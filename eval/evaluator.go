@@ -0,0 +1,249 @@
+// Copyright (c) 2014 Feng Wang <wffrank1987@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language
+
+package eval
+
+import (
+	"fmt"
+	"runtime"
+	"sort"
+	"sync"
+
+	"github.com/numb3r3/gorec/core"
+	"github.com/numb3r3/gorec/data"
+)
+
+// The options of the evaluator
+type EvaluatorOptions struct {
+
+	// The cutoff of the top-N metrics, the full ranking of the NumProducts
+	// products is evaluated if not positive
+	K int
+
+	// The explicit ratings below the threshold are not relevant,
+	// the implicit events are always relevant
+	RelevanceThreshold float64
+
+	// The number of the products for the AUC, inferred from the records
+	// and the history if not positive
+	NumProducts int
+
+	// The training history, whose products of a user are not counted as
+	// the candidates of the user for the AUC. Can be nil.
+	History *core.HistoryIndex
+
+	// The number of the concurrent workers, runtime.NumCPU() if not positive
+	NumWorkers int
+}
+
+func DefaultEvaluatorOptions() EvaluatorOptions {
+	return EvaluatorOptions{K: 10, RelevanceThreshold: 4}
+}
+
+// The metrics of one user
+type UserMetrics struct {
+	UserId int
+
+	NumRelevant int
+
+	Precision, Recall, NDCG, AveragePrecision, ReciprocalRank, AUC, Hit float64
+}
+
+// The metrics averaged over the users, and the rating errors over the
+// explicit records if the recommender is a core.Predictor
+type Report struct {
+	K int
+
+	NumUsers int
+
+	Precision, Recall, NDCG, MAP, MRR, AUC, HitRate float64
+
+	NumRatings int
+
+	RMSE, MAE float64
+
+	// user id -> the metrics of the user
+	Users map[int]*UserMetrics
+}
+
+func (r *Report) String() string {
+	s := fmt.Sprintf("users=%d Precision@%d=%.4f Recall@%d=%.4f NDCG@%d=%.4f MAP=%.4f MRR=%.4f AUC=%.4f HitRate=%.4f",
+		r.NumUsers, r.K, r.Precision, r.K, r.Recall, r.K, r.NDCG, r.MAP, r.MRR, r.AUC, r.HitRate)
+	if r.NumRatings > 0 {
+		s += fmt.Sprintf(" ratings=%d RMSE=%.4f MAE=%.4f", r.NumRatings, r.RMSE, r.MAE)
+	}
+	return s
+}
+
+// Get the metrics of the users sorted by user id
+func (r *Report) UserList() []*UserMetrics {
+	users := make([]*UserMetrics, 0, len(r.Users))
+	for _, m := range r.Users {
+		users = append(users, m)
+	}
+	sort.Slice(users, func(a, b int) bool { return users[a].UserId < users[b].UserId })
+	return users
+}
+
+// The offline evaluator of the recommenders over the held-out records
+type Evaluator struct {
+	options EvaluatorOptions
+}
+
+func NewEvaluator(options EvaluatorOptions) *Evaluator {
+	return &Evaluator{options}
+}
+
+// Evaluate the fitted recommender on the held-out dataset made by
+// core.RecordsDataset
+func (e *Evaluator) Evaluate(recommender core.Recommender, test data.Dataset) *Report {
+	return e.EvaluateRecords(recommender, core.DatasetRecords(test))
+}
+
+// Evaluate the fitted recommender on the held-out records
+func (e *Evaluator) EvaluateRecords(recommender core.Recommender, test []*core.Record) *Report {
+	relevant := make(map[int]map[int]bool)
+	for _, r := range test {
+		if r.IsExplicit() && r.Value < e.options.RelevanceThreshold {
+			continue
+		}
+		if relevant[r.UserId] == nil {
+			relevant[r.UserId] = make(map[int]bool)
+		}
+		relevant[r.UserId][r.ProductId] = true
+	}
+	numProducts := e.numProducts(test)
+
+	report := &Report{K: e.options.K, Users: make(map[int]*UserMetrics)}
+	users := make(chan int)
+	results := make(chan *UserMetrics)
+	numWorkers := e.options.NumWorkers
+	if numWorkers <= 0 {
+		numWorkers = runtime.NumCPU()
+	}
+	var wg sync.WaitGroup
+	for w := 0; w < numWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for u := range users {
+				results <- e.evaluateUser(recommender, u, relevant[u], numProducts)
+			}
+		}()
+	}
+	go func() {
+		for u := range relevant {
+			users <- u
+		}
+		close(users)
+		wg.Wait()
+		close(results)
+	}()
+	for m := range results {
+		report.Users[m.UserId] = m
+	}
+	// sum in the order of the users, so that the report is reproducible
+	for _, m := range report.UserList() {
+		report.Precision += m.Precision
+		report.Recall += m.Recall
+		report.NDCG += m.NDCG
+		report.MAP += m.AveragePrecision
+		report.MRR += m.ReciprocalRank
+		report.AUC += m.AUC
+		report.HitRate += m.Hit
+	}
+	if n := float64(len(report.Users)); n > 0 {
+		report.NumUsers = len(report.Users)
+		report.Precision /= n
+		report.Recall /= n
+		report.NDCG /= n
+		report.MAP /= n
+		report.MRR /= n
+		report.AUC /= n
+		report.HitRate /= n
+	}
+
+	if predictor, ok := recommender.(core.Predictor); ok {
+		var predictions, actuals []float64
+		for _, r := range test {
+			if r.IsExplicit() {
+				predictions = append(predictions, predictor.Predict(r.UserId, r.ProductId))
+				actuals = append(actuals, r.Value)
+			}
+		}
+		report.NumRatings = len(predictions)
+		report.RMSE = RMSE(predictions, actuals)
+		report.MAE = MAE(predictions, actuals)
+	}
+	return report
+}
+
+func (e *Evaluator) evaluateUser(recommender core.Recommender, userId int, relevant map[int]bool, numProducts int) *UserMetrics {
+	k := e.options.K
+	n := k
+	if n <= 0 {
+		n = numProducts
+	}
+	products := recommender.Recommend(userId, nil, n)
+	ranked := make([]int, len(products))
+	for i, p := range products {
+		ranked[i] = p.ProductId
+	}
+
+	// the products in the training history are not candidates, neither
+	// counted nor ranked for the AUC
+	numCandidates, candidates := numProducts, ranked
+	if e.options.History != nil {
+		if history := e.options.History.GetHistory(userId); history != nil {
+			for id := range history.Seen {
+				if !relevant[id] {
+					numCandidates--
+				}
+			}
+			candidates = make([]int, 0, len(ranked))
+			for _, id := range ranked {
+				if _, seen := history.Seen[id]; !seen || relevant[id] {
+					candidates = append(candidates, id)
+				}
+			}
+		}
+	}
+	return &UserMetrics{
+		UserId:           userId,
+		NumRelevant:      len(relevant),
+		Precision:        Precision(ranked, relevant, k),
+		Recall:           Recall(ranked, relevant, k),
+		NDCG:             NDCG(ranked, relevant, k),
+		AveragePrecision: AveragePrecision(ranked, relevant, k),
+		ReciprocalRank:   ReciprocalRank(ranked, relevant, k),
+		AUC:              AUC(candidates, relevant, numCandidates),
+		Hit:              HitRate(ranked, relevant, k),
+	}
+}
+
+func (e *Evaluator) numProducts(test []*core.Record) int {
+	if e.options.NumProducts > 0 {
+		return e.options.NumProducts
+	}
+	_, n := core.RecordsDimension(test)
+	if e.options.History != nil {
+		for _, u := range e.options.History.Users() {
+			for id := range e.options.History.GetHistory(u).Seen {
+				if id+1 > n {
+					n = id + 1
+				}
+			}
+		}
+	}
+	return n
+}
@@ -0,0 +1,173 @@
+// Copyright (c) 2014 Feng Wang <wffrank1987@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language
+
+package eval
+
+import (
+	gomath "math"
+)
+
+// The ranking metrics of a list of product ids ordered by descending score
+// against the set of the relevant products. The list is cut at k, and the
+// whole list is used if k is not positive.
+
+func cut(ranked []int, k int) []int {
+	if k > 0 && len(ranked) > k {
+		return ranked[:k]
+	}
+	return ranked
+}
+
+func hits(ranked []int, relevant map[int]bool) int {
+	n := 0
+	for _, id := range ranked {
+		if relevant[id] {
+			n++
+		}
+	}
+	return n
+}
+
+// Get the fraction of the top-k products which are relevant
+func Precision(ranked []int, relevant map[int]bool, k int) float64 {
+	if k <= 0 {
+		k = len(ranked)
+	}
+	if k == 0 {
+		return 0
+	}
+	return float64(hits(cut(ranked, k), relevant)) / float64(k)
+}
+
+// Get the fraction of the relevant products in the top-k
+func Recall(ranked []int, relevant map[int]bool, k int) float64 {
+	if len(relevant) == 0 {
+		return 0
+	}
+	return float64(hits(cut(ranked, k), relevant)) / float64(len(relevant))
+}
+
+// Get 1 if any of the top-k products is relevant, otherwise 0
+func HitRate(ranked []int, relevant map[int]bool, k int) float64 {
+	if hits(cut(ranked, k), relevant) > 0 {
+		return 1
+	}
+	return 0
+}
+
+// Get the normalized discounted cumulative gain of the top-k with the
+// binary relevance
+func NDCG(ranked []int, relevant map[int]bool, k int) float64 {
+	ranked = cut(ranked, k)
+	var dcg, idcg float64
+	for i, id := range ranked {
+		if relevant[id] {
+			dcg += 1 / gomath.Log2(float64(i+2))
+		}
+	}
+	ideal := len(relevant)
+	if k > 0 && ideal > k {
+		ideal = k
+	}
+	for i := 0; i < ideal; i++ {
+		idcg += 1 / gomath.Log2(float64(i+2))
+	}
+	if idcg == 0 {
+		return 0
+	}
+	return dcg / idcg
+}
+
+// Get the average of the precisions at the ranks of the relevant products
+// in the top-k, over min(k, number of relevant products)
+func AveragePrecision(ranked []int, relevant map[int]bool, k int) float64 {
+	ranked = cut(ranked, k)
+	var sum float64
+	n := 0
+	for i, id := range ranked {
+		if relevant[id] {
+			n++
+			sum += float64(n) / float64(i+1)
+		}
+	}
+	ideal := len(relevant)
+	if k > 0 && ideal > k {
+		ideal = k
+	}
+	if ideal == 0 {
+		return 0
+	}
+	return sum / float64(ideal)
+}
+
+// Get the reciprocal of the rank of the first relevant product in the top-k
+func ReciprocalRank(ranked []int, relevant map[int]bool, k int) float64 {
+	for i, id := range cut(ranked, k) {
+		if relevant[id] {
+			return 1 / float64(i+1)
+		}
+	}
+	return 0
+}
+
+// Get the area under the ROC curve, the probability that a relevant product
+// is ranked above an irrelevant one, among numCandidates products of which
+// only the ranked ones are ordered. The products out of the list are tied
+// below the list.
+func AUC(ranked []int, relevant map[int]bool, numCandidates int) float64 {
+	numRelevant := len(relevant)
+	numIrrelevant := numCandidates - numRelevant
+	if numRelevant == 0 || numIrrelevant <= 0 {
+		return 0
+	}
+	var correct float64
+	irrelevantAbove, relevantListed := 0, 0
+	for _, id := range ranked {
+		if relevant[id] {
+			correct += float64(numIrrelevant - irrelevantAbove)
+			relevantListed++
+		} else {
+			irrelevantAbove++
+		}
+	}
+	// the unlisted relevant products tie with the unlisted irrelevant ones
+	unlistedIrrelevant := numIrrelevant - irrelevantAbove
+	if unlistedIrrelevant > 0 {
+		correct += float64(numRelevant-relevantListed) * float64(unlistedIrrelevant) / 2
+	}
+	return correct / float64(numRelevant*numIrrelevant)
+}
+
+// Get the root mean squared error of the predictions
+func RMSE(predictions, actuals []float64) float64 {
+	if len(predictions) == 0 {
+		return 0
+	}
+	var sum float64
+	for i, p := range predictions {
+		sum += (p - actuals[i]) * (p - actuals[i])
+	}
+	return gomath.Sqrt(sum / float64(len(predictions)))
+}
+
+// Get the mean absolute error of the predictions
+func MAE(predictions, actuals []float64) float64 {
+	if len(predictions) == 0 {
+		return 0
+	}
+	var sum float64
+	for i, p := range predictions {
+		sum += gomath.Abs(p - actuals[i])
+	}
+	return sum / float64(len(predictions))
+}
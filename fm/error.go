@@ -0,0 +1,48 @@
+// Copyright (c) 2014 Feng Wang <wffrank1987@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language
+
+package fm
+
+import (
+	"fmt"
+)
+
+const (
+	// The instance has more dense features than NumFeatures
+	errorTooManyFeatures = iota
+	// The dataset has no instance
+	errorEmptyDataset
+)
+
+type error_ int
+
+func (e error_) Error() string {
+	switch e {
+	case errorTooManyFeatures:
+		return "Instance has more features than NumFeatures"
+	case errorEmptyDataset:
+		return "No instance to train"
+	}
+	return fmt.Sprintf("Unknown error code %d", e)
+}
+
+func (e error_) String() string {
+	return e.Error()
+}
+
+var (
+	// The instance has more dense features than NumFeatures
+	ErrorTooManyFeatures error_ = error_(errorTooManyFeatures)
+	// The dataset has no instance
+	ErrorEmptyDataset error_ = error_(errorEmptyDataset)
+)
@@ -0,0 +1,341 @@
+// Copyright (c) 2014 Feng Wang <wffrank1987@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language
+
+package fm
+
+import (
+	"encoding/gob"
+	"io"
+	gomath "math"
+	"math/rand"
+	"sort"
+
+	"github.com/numb3r3/gorec/core"
+	"github.com/numb3r3/gorec/data"
+	"github.com/numb3r3/gorec/utils"
+)
+
+// The learning task of the model
+type Task int
+
+const (
+	// Predict the output value, trained with the squared loss
+	Regression Task = iota
+	// Predict the probability of the positive label (Label > 0),
+	// trained with the logistic loss
+	Classification
+)
+
+// The optimizer updating the parameters
+type Optimizer int
+
+const (
+	// The plain stochastic gradient descent
+	SGD Optimizer = iota
+	// SGD with the per-parameter learning rate lr / sqrt(sum of squared gradients)
+	AdaGrad
+)
+
+// The options of the factorization machine
+type FMOptions struct {
+	Task Task
+
+	Optimizer Optimizer
+
+	// The number of latent factors of each feature
+	NumFactors int
+
+	// The number of passes over the dataset
+	NumEpochs int
+
+	LearningRate float64
+
+	// The L2 regularization of the linear weights
+	Regularization float64
+
+	// The L2 regularization of the factors
+	FactorRegularization float64
+
+	// The standard deviation of the initial factors
+	InitStd float64
+
+	// The seed of the random number generator
+	Seed int64
+
+	// The dimension of the instance Features, the named features take
+	// the ids after it so they never share weights with the dense ones.
+	// The instances with more Features are rejected.
+	NumFeatures int
+}
+
+func DefaultFMOptions() FMOptions {
+	return FMOptions{
+		Task:                 Regression,
+		Optimizer:            AdaGrad,
+		NumFactors:           8,
+		NumEpochs:            10,
+		LearningRate:         0.1,
+		Regularization:       0.0001,
+		FactorRegularization: 0.0001,
+		InitStd:              0.01,
+		Seed:                 1,
+	}
+}
+
+// The second-order factorization machine (Rendle, 2010)
+//
+//	y(x) = w0 + sum_i w_i x_i + sum_{i<j} <v_i, v_j> x_i x_j
+//
+// over the non-zero Features and NamedFeatures of the instances.
+type FM struct {
+	options FMOptions
+
+	w0 float64
+	w  []float64
+	v  [][]float64
+
+	// The sums of the squared gradients for AdaGrad
+	g0 float64
+	gw []float64
+	gv [][]float64
+
+	// The ids of the named features
+	dict *utils.Dictionary
+
+	rng *rand.Rand
+}
+
+func NewFM(options FMOptions) *FM {
+	m := new(FM)
+	m.options = options
+	m.dict = utils.NewDictionary(options.NumFeatures)
+	m.rng = rand.New(rand.NewSource(options.Seed))
+	return m
+}
+
+// A non-zero feature of an instance
+type feature struct {
+	id    int
+	value float64
+}
+
+// Extract the non-zero features of the instance. The unknown named
+// features are added to the model if grow is true, or ignored otherwise.
+func (m *FM) features(instance *data.Instance, grow bool) ([]feature, error) {
+	if len(instance.Features) > m.options.NumFeatures {
+		return nil, ErrorTooManyFeatures
+	}
+	var x []feature
+	for i, value := range instance.Features {
+		if value != 0 {
+			x = append(x, feature{i, value})
+		}
+	}
+	// in the order of the names, so that the new features get their ids
+	// and initial factors reproducibly
+	names := make([]string, 0, len(instance.NamedFeatures))
+	for name, value := range instance.NamedFeatures {
+		if value != 0 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		value := instance.NamedFeatures[name]
+		id := m.dict.GetIdFromName(name)
+		if id < 0 {
+			if !grow {
+				continue
+			}
+			id = m.dict.AddName(name)
+		}
+		x = append(x, feature{id, value})
+	}
+	if grow {
+		for _, f := range x {
+			m.grow(f.id)
+		}
+	}
+	return x, nil
+}
+
+// Make room for the parameters of the feature
+func (m *FM) grow(id int) {
+	for len(m.w) <= id {
+		factors := make([]float64, m.options.NumFactors)
+		for f := range factors {
+			factors[f] = m.rng.NormFloat64() * m.options.InitStd
+		}
+		m.w = append(m.w, 0)
+		m.v = append(m.v, factors)
+		m.gw = append(m.gw, 0)
+		m.gv = append(m.gv, make([]float64, m.options.NumFactors))
+	}
+}
+
+// Compute y(x) and the sums sum_i v_if x_i used by the gradients
+func (m *FM) score(x []feature) (float64, []float64) {
+	k := m.options.NumFactors
+	sums := make([]float64, k)
+	y := m.w0
+	var squares float64
+	for _, xi := range x {
+		if xi.id >= len(m.w) {
+			continue
+		}
+		y += m.w[xi.id] * xi.value
+		for f, vf := range m.v[xi.id] {
+			t := vf * xi.value
+			sums[f] += t
+			squares += t * t
+		}
+	}
+	var interactions float64
+	for _, s := range sums {
+		interactions += s * s
+	}
+	return y + 0.5*(interactions-squares), sums
+}
+
+// The gradient of the loss with respect to y(x)
+func (m *FM) lossGradient(y float64, output *data.InstanceOutput) float64 {
+	if m.options.Task == Classification {
+		target := 0.0
+		if output.Label > 0 {
+			target = 1
+		}
+		return sigmoid(y) - target
+	}
+	return y - output.Value
+}
+
+// Train the model over the instances of the dataset, the instances
+// without output are skipped
+func (m *FM) Train(dataset data.Dataset) error {
+	if dataset.NumInstance() == 0 {
+		return ErrorEmptyDataset
+	}
+	it := dataset.CreateIterator()
+	for epoch := 0; epoch < m.options.NumEpochs; epoch++ {
+		for it.Start(); !it.End(); it.Next() {
+			if err := m.Update(it.GetInstance()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Make one stochastic update on the instance, the instance without
+// output is skipped
+func (m *FM) Update(instance *data.Instance) error {
+	if instance.Output == nil {
+		return nil
+	}
+	o := m.options
+	x, err := m.features(instance, true)
+	if err != nil {
+		return err
+	}
+	y, sums := m.score(x)
+	g := m.lossGradient(y, instance.Output)
+
+	m.w0 -= m.step(g, &m.g0)
+	for _, xi := range x {
+		m.w[xi.id] -= m.step(g*xi.value+o.Regularization*m.w[xi.id], &m.gw[xi.id])
+		vi, gvi := m.v[xi.id], m.gv[xi.id]
+		for f := range vi {
+			grad := g*xi.value*(sums[f]-vi[f]*xi.value) + o.FactorRegularization*vi[f]
+			vi[f] -= m.step(grad, &gvi[f])
+		}
+	}
+	return nil
+}
+
+// Get the step of the gradient by the optimizer
+func (m *FM) step(grad float64, accumulated *float64) float64 {
+	if m.options.Optimizer == AdaGrad {
+		*accumulated += grad * grad
+		return m.options.LearningRate * grad / gomath.Sqrt(*accumulated+1e-8)
+	}
+	return m.options.LearningRate * grad
+}
+
+// Predict the output value for regression, or the probability of the
+// positive label for classification. The instance with more Features
+// than NumFeatures is scored by the bias only.
+func (m *FM) Predict(instance *data.Instance) float64 {
+	x, _ := m.features(instance, false)
+	y, _ := m.score(x)
+	if m.options.Task == Classification {
+		return sigmoid(y)
+	}
+	return y
+}
+
+// Rank the candidate products by the prediction on their instances,
+// which hold the features of the user, the product and the context
+func (m *FM) Rank(candidates map[int]*data.Instance, n int) []*core.ScoredProduct {
+	scores := make(map[int]float64, len(candidates))
+	for id, instance := range candidates {
+		scores[id] = m.Predict(instance)
+	}
+	return core.TopN(scores, n)
+}
+
+func sigmoid(y float64) float64 {
+	return 1 / (1 + gomath.Exp(-y))
+}
+
+// The persisted form of the model
+type fmSnapshot struct {
+	Options FMOptions
+	W0      float64
+	W       []float64
+	V       [][]float64
+	G0      float64
+	Gw      []float64
+	Gv      [][]float64
+	Names   []string
+}
+
+// Write the model weights to w
+func (m *FM) Save(w io.Writer) error {
+	s := fmSnapshot{
+		Options: m.options,
+		W0:      m.w0, W: m.w, V: m.v,
+		G0: m.g0, Gw: m.gw, Gv: m.gv,
+	}
+	for id := m.options.NumFeatures; id < m.dict.MaxId(); id++ {
+		s.Names = append(s.Names, m.dict.GetNameFromId(id))
+	}
+	return gob.NewEncoder(w).Encode(&s)
+}
+
+// Read the model weights written by Save. The factors of the features
+// added afterwards are drawn from a seed derived from Seed and the number
+// of the features, so they do not repeat the draws of the saved ones.
+func Load(r io.Reader) (*FM, error) {
+	var s fmSnapshot
+	if err := gob.NewDecoder(r).Decode(&s); err != nil {
+		return nil, err
+	}
+	m := NewFM(s.Options)
+	m.w0, m.w, m.v = s.W0, s.W, s.V
+	m.g0, m.gw, m.gv = s.G0, s.Gw, s.Gv
+	for _, name := range s.Names {
+		m.dict.AddName(name)
+	}
+	m.rng = rand.New(rand.NewSource(s.Options.Seed + int64(len(s.W))))
+	return m, nil
+}
@@ -0,0 +1,137 @@
+// Copyright (c) 2014 Feng Wang <wffrank1987@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language
+
+package fm
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/numb3r3/gorec/data"
+	"github.com/numb3r3/gorec/utils"
+	"testing"
+)
+
+// The rating of a user on an item is 1 if both are even or both are odd,
+// which only the user-item interaction can learn
+func xorInstances() []*data.Instance {
+	var instances []*data.Instance
+	for u := 0; u < 4; u++ {
+		for i := 0; i < 4; i++ {
+			value := 0.0
+			if u%2 == i%2 {
+				value = 1
+			}
+			instances = append(instances, &data.Instance{
+				Features:      []float64{1},
+				NamedFeatures: map[string]float64{fmt.Sprint("user=", u): 1, fmt.Sprint("item=", i): 1},
+				Output:        &data.InstanceOutput{Value: value},
+			})
+		}
+	}
+	return instances
+}
+
+func TestFM(t *testing.T) {
+	options := DefaultFMOptions()
+	options.NumFeatures = 1
+	options.NumEpochs = 300
+	model := NewFM(options)
+	instances := xorInstances()
+	for epoch := 0; epoch < options.NumEpochs; epoch++ {
+		for _, instance := range instances {
+			if err := model.Update(instance); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	for _, instance := range instances {
+		utils.ExpectNear(t, instance.Output.Value, model.Predict(instance), 0.1)
+	}
+
+	tooLong := &data.Instance{Features: []float64{1, 1}, Output: &data.InstanceOutput{Value: 1}}
+	utils.Expect(t, ErrorTooManyFeatures.Error(), model.Update(tooLong))
+}
+
+func TestNamedFeaturesOffset(t *testing.T) {
+	options := DefaultFMOptions()
+	options.NumFeatures = 2
+	model := NewFM(options)
+	model.Update(&data.Instance{Features: []float64{0, 1}, Output: &data.InstanceOutput{Value: 1}})
+	model.Update(&data.Instance{NamedFeatures: map[string]float64{"user=1": 1}, Output: &data.InstanceOutput{Value: -1}})
+
+	// the named feature takes the id after the dense features
+	utils.Expect(t, "2", model.dict.GetIdFromName("user=1"))
+	dense := model.Predict(&data.Instance{Features: []float64{0, 1}})
+	named := model.Predict(&data.Instance{NamedFeatures: map[string]float64{"user=1": 1}})
+	utils.Expect(t, "true", dense > named)
+}
+
+func TestSaveLoad(t *testing.T) {
+	options := DefaultFMOptions()
+	options.NumFeatures = 1
+	model := NewFM(options)
+	instances := xorInstances()
+	for _, instance := range instances {
+		model.Update(instance)
+	}
+
+	var buffer bytes.Buffer
+	if err := model.Save(&buffer); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(&buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, instance := range instances {
+		utils.ExpectNear(t, model.Predict(instance), loaded.Predict(instance), 1e-12)
+	}
+	// the loaded model keeps learning with the same feature ids
+	model.Update(instances[0])
+	loaded.Update(instances[0])
+	utils.ExpectNear(t, model.Predict(instances[1]), loaded.Predict(instances[1]), 1e-12)
+}
+
+func TestReproducible(t *testing.T) {
+	options := DefaultFMOptions()
+	options.NumFeatures = 1
+	first, second := NewFM(options), NewFM(options)
+	instances := xorInstances()
+	for _, instance := range instances {
+		first.Update(instance)
+		second.Update(instance)
+	}
+	for _, instance := range instances {
+		utils.ExpectNear(t, first.Predict(instance), second.Predict(instance), 1e-12)
+	}
+}
+
+func TestLoadFreshDraws(t *testing.T) {
+	options := DefaultFMOptions()
+	model := NewFM(options)
+	model.Update(&data.Instance{NamedFeatures: map[string]float64{"a": 1}, Output: &data.InstanceOutput{Value: 1}})
+
+	var buffer bytes.Buffer
+	if err := model.Save(&buffer); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(&buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the initial factors of a new feature do not repeat the first draws
+	fresh := NewFM(options)
+	fresh.grow(0)
+	loaded.grow(1)
+	utils.Expect(t, "false", fmt.Sprint(fresh.v[0]) == fmt.Sprint(loaded.v[1]))
+}
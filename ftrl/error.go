@@ -0,0 +1,48 @@
+// Copyright (c) 2014 Feng Wang <wffrank1987@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language
+
+package ftrl
+
+import (
+	"fmt"
+)
+
+const (
+	// NumBits is larger than the 32 bits of the feature hash
+	errorIllegalNumBits = iota
+	// The weights of the saved model do not match its NumBits
+	errorCorruptedModel
+)
+
+type error_ int
+
+func (e error_) Error() string {
+	switch e {
+	case errorIllegalNumBits:
+		return "NumBits must not be larger than 32"
+	case errorCorruptedModel:
+		return "The size of the saved weights does not match NumBits"
+	}
+	return fmt.Sprintf("Unknown error code %d", e)
+}
+
+func (e error_) String() string {
+	return e.Error()
+}
+
+var (
+	// NumBits is larger than the 32 bits of the feature hash
+	ErrorIllegalNumBits error_ = error_(errorIllegalNumBits)
+	// The weights of the saved model do not match its NumBits
+	ErrorCorruptedModel error_ = error_(errorCorruptedModel)
+)
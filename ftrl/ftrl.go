@@ -0,0 +1,224 @@
+// Copyright (c) 2014 Feng Wang <wffrank1987@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language
+
+package ftrl
+
+import (
+	"encoding/gob"
+	"hash/fnv"
+	"io"
+	gomath "math"
+	"strconv"
+
+	"github.com/numb3r3/gorec/data"
+)
+
+// The options of the FTRL-Proximal learner
+type FTRLOptions struct {
+
+	// The learning rate parameters, the per-coordinate learning rate
+	// is Alpha / (Beta + sqrt(sum of squared gradients))
+	Alpha, Beta float64
+
+	// The L1 and L2 regularization
+	L1, L2 float64
+
+	// The features are hashed into 2^NumBits buckets, at most 32
+	NumBits uint
+
+	// The rate at which the negative instances were kept when the
+	// training data was down-sampled, the predictions are corrected
+	// back to the original distribution. 1 means no down-sampling.
+	NegativeSampleRate float64
+}
+
+func DefaultFTRLOptions() FTRLOptions {
+	return FTRLOptions{
+		Alpha:              0.1,
+		Beta:               1,
+		L1:                 1,
+		L2:                 1,
+		NumBits:            20,
+		NegativeSampleRate: 1,
+	}
+}
+
+// The online logistic regression trained by FTRL-Proximal
+// (McMahan et al., 2013) for click-through rate prediction.
+// The features of an instance are its non-zero Features and
+// NamedFeatures hashed into a fixed-size weight space, plus a bias.
+type FTRL struct {
+	options FTRLOptions
+
+	// The per-coordinate state of FTRL-Proximal
+	z, n []float64
+
+	mask uint32
+}
+
+// Create the learner, ErrorIllegalNumBits is returned if NumBits is
+// larger than 32
+func NewFTRL(options FTRLOptions) (*FTRL, error) {
+	if options.NumBits > 32 {
+		return nil, ErrorIllegalNumBits
+	}
+	m := new(FTRL)
+	m.options = options
+	size := 1 << options.NumBits
+	m.z = make([]float64, size)
+	m.n = make([]float64, size)
+	m.mask = uint32(size - 1)
+	return m, nil
+}
+
+// A hashed feature of an instance
+type feature struct {
+	index int
+	value float64
+}
+
+func (m *FTRL) hash(name string) int {
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	return int(h.Sum32() & m.mask)
+}
+
+// Hash the non-zero features of the instance and the bias
+func (m *FTRL) features(instance *data.Instance) []feature {
+	x := []feature{{m.hash("__bias__"), 1}}
+	for i, value := range instance.Features {
+		if value != 0 {
+			x = append(x, feature{m.hash("#" + strconv.Itoa(i)), value})
+		}
+	}
+	for name, value := range instance.NamedFeatures {
+		if value != 0 {
+			x = append(x, feature{m.hash(name), value})
+		}
+	}
+	return x
+}
+
+// Get the weight of the coordinate from its lazy state
+func (m *FTRL) weight(i int) float64 {
+	o := m.options
+	z := m.z[i]
+	if gomath.Abs(z) <= o.L1 {
+		return 0
+	}
+	sign := 1.0
+	if z < 0 {
+		sign = -1
+	}
+	return -(z - sign*o.L1) / ((o.Beta+gomath.Sqrt(m.n[i]))/o.Alpha + o.L2)
+}
+
+func (m *FTRL) probability(x []feature) float64 {
+	var s float64
+	for _, f := range x {
+		s += m.weight(f.index) * f.value
+	}
+	// bound the score to avoid overflow
+	s = gomath.Max(gomath.Min(s, 35), -35)
+	return 1 / (1 + gomath.Exp(-s))
+}
+
+// Train the model in a single pass over the instances of the iterator,
+// the instances without output are skipped
+func (m *FTRL) Train(it data.DatasetIterator) {
+	for it.Start(); !it.End(); it.Next() {
+		m.Update(it.GetInstance())
+	}
+}
+
+// Update the model with one instance, the label is positive if
+// Output.Label > 0. It returns the probability predicted before the update.
+func (m *FTRL) Update(instance *data.Instance) float64 {
+	x := m.features(instance)
+	p := m.probability(x)
+	if instance.Output == nil {
+		return p
+	}
+	y := 0.0
+	if instance.Output.Label > 0 {
+		y = 1
+	}
+	for _, f := range x {
+		i := f.index
+		g := (p - y) * f.value
+		sigma := (gomath.Sqrt(m.n[i]+g*g) - gomath.Sqrt(m.n[i])) / m.options.Alpha
+		m.z[i] += g - sigma*m.weight(i)
+		m.n[i] += g * g
+	}
+	return p
+}
+
+// Predict the probability of the positive label, corrected for the
+// negative down-sampling
+func (m *FTRL) Predict(instance *data.Instance) float64 {
+	p := m.probability(m.features(instance))
+	if w := m.options.NegativeSampleRate; w > 0 && w < 1 {
+		p = p / (p + (1-p)/w)
+	}
+	return p
+}
+
+// Predict the instance and write the probabilities of the labels 0 and 1
+// into Output.LabelLikelihood, the other fields of Output are kept
+func (m *FTRL) PredictOutput(instance *data.Instance) {
+	p := m.Predict(instance)
+	if instance.Output == nil {
+		instance.Output = new(data.InstanceOutput)
+	}
+	instance.Output.LabelLikelihood = []float64{1 - p, p}
+}
+
+// Get the number of the non-zero weights
+func (m *FTRL) NumNonZeros() int {
+	count := 0
+	for i := range m.z {
+		if m.weight(i) != 0 {
+			count++
+		}
+	}
+	return count
+}
+
+// The persisted form of the model
+type ftrlSnapshot struct {
+	Options FTRLOptions
+	Z, N    []float64
+}
+
+// Write the model state to w
+func (m *FTRL) Save(w io.Writer) error {
+	return gob.NewEncoder(w).Encode(&ftrlSnapshot{m.options, m.z, m.n})
+}
+
+// Read the model state written by Save, ErrorCorruptedModel is returned
+// if the size of the state does not match NumBits
+func Load(r io.Reader) (*FTRL, error) {
+	var s ftrlSnapshot
+	if err := gob.NewDecoder(r).Decode(&s); err != nil {
+		return nil, err
+	}
+	if s.Options.NumBits > 32 {
+		return nil, ErrorIllegalNumBits
+	}
+	if len(s.Z) != 1<<s.Options.NumBits || len(s.N) != len(s.Z) {
+		return nil, ErrorCorruptedModel
+	}
+	m := &FTRL{options: s.Options, z: s.Z, n: s.N}
+	m.mask = uint32(len(s.Z) - 1)
+	return m, nil
+}
@@ -0,0 +1,115 @@
+// Copyright (c) 2014 Feng Wang <wffrank1987@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language
+
+package ftrl
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"github.com/numb3r3/gorec/data"
+	"github.com/numb3r3/gorec/utils"
+	"math/rand"
+	"testing"
+)
+
+// The label is 1 if the signal feature is on, the noise features are random
+func instances(n int) []*data.Instance {
+	rng := rand.New(rand.NewSource(1))
+	var result []*data.Instance
+	for i := 0; i < n; i++ {
+		label := rng.Intn(2)
+		features := map[string]float64{fmt.Sprint("noise=", rng.Intn(50)): 1}
+		if label == 1 {
+			features["signal"] = 1
+		}
+		result = append(result, &data.Instance{
+			NamedFeatures: features,
+			Output:        &data.InstanceOutput{Label: label},
+		})
+	}
+	return result
+}
+
+func train(t *testing.T, options FTRLOptions) *FTRL {
+	model, err := NewFTRL(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, instance := range instances(2000) {
+		model.Update(instance)
+	}
+	return model
+}
+
+func TestFTRL(t *testing.T) {
+	model := train(t, DefaultFTRLOptions())
+	positive := &data.Instance{NamedFeatures: map[string]float64{"signal": 1, "noise=1": 1}}
+	negative := &data.Instance{NamedFeatures: map[string]float64{"noise=1": 1}}
+	utils.Expect(t, "true", model.Predict(positive) > 0.8)
+	utils.Expect(t, "true", model.Predict(negative) < 0.3)
+
+	// only the likelihood is written, the label is kept
+	instance := &data.Instance{
+		NamedFeatures: map[string]float64{"signal": 1},
+		Output:        &data.InstanceOutput{Label: 0},
+	}
+	model.PredictOutput(instance)
+	utils.Expect(t, "0", instance.Output.Label)
+	utils.ExpectNear(t, 1, instance.Output.LabelLikelihood[0]+instance.Output.LabelLikelihood[1], 1e-12)
+	utils.Expect(t, "true", instance.Output.LabelLikelihood[1] > 0.8)
+
+	options := DefaultFTRLOptions()
+	options.NumBits = 33
+	_, err := NewFTRL(options)
+	utils.Expect(t, ErrorIllegalNumBits.Error(), err)
+}
+
+func TestL1Sparsity(t *testing.T) {
+	options := DefaultFTRLOptions()
+	options.L1 = 0
+	dense := train(t, options)
+	options.L1 = 10
+	sparse := train(t, options)
+
+	// the bias, the signal and the 50 noise features
+	utils.Expect(t, "52", dense.NumNonZeros())
+	utils.Expect(t, "true", sparse.NumNonZeros() < dense.NumNonZeros())
+	utils.Expect(t, "true", sparse.NumNonZeros() > 0)
+}
+
+func TestSaveLoad(t *testing.T) {
+	model := train(t, DefaultFTRLOptions())
+	var buffer bytes.Buffer
+	if err := model.Save(&buffer); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(&buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	utils.Expect(t, fmt.Sprint(model.NumNonZeros()), loaded.NumNonZeros())
+	for _, instance := range instances(20) {
+		utils.ExpectNear(t, model.Predict(instance), loaded.Predict(instance), 1e-12)
+	}
+
+	// the weights of 2^20 buckets saved with NumBits 4
+	options := DefaultFTRLOptions()
+	options.NumBits = 4
+	buffer.Reset()
+	if err := gob.NewEncoder(&buffer).Encode(&ftrlSnapshot{options, model.z, model.n}); err != nil {
+		t.Fatal(err)
+	}
+	_, err = Load(&buffer)
+	utils.Expect(t, ErrorCorruptedModel.Error(), err)
+}
@@ -0,0 +1,261 @@
+// Copyright (c) 2014 Feng Wang <wffrank1987@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language
+
+package graph
+
+import (
+	gomath "math"
+	"runtime"
+	"sort"
+	"sync"
+
+	"github.com/numb3r3/gorec/core"
+	"github.com/numb3r3/gorec/math"
+)
+
+// The options of the random-walk recommenders
+type RandomWalkOptions struct {
+
+	// The transition probabilities are raised to the power of Alpha
+	Alpha float64
+
+	// The score of the destination item j is divided by popularity(j)^Beta,
+	// the model is P3alpha if it is zero and RP3beta otherwise
+	Beta float64
+
+	// The number of the neighbors kept per item, unlimited if not positive
+	K int
+
+	// Whether to normalize each row of the item-item matrix to sum to 1
+	NormalizeRows bool
+
+	// Whether to regard every interaction as 1 instead of its value
+	Binarize bool
+
+	// The weights of the implicit events, nil for the default weights
+	Weights core.EventWeights
+}
+
+func DefaultP3AlphaOptions() RandomWalkOptions {
+	return RandomWalkOptions{Alpha: 1, K: 100, Binarize: true}
+}
+
+func DefaultRP3BetaOptions() RandomWalkOptions {
+	return RandomWalkOptions{Alpha: 1, Beta: 0.5, K: 100, Binarize: true}
+}
+
+// An item reached from another item, and the transition score
+type neighbor struct {
+	item  int
+	score float64
+}
+
+// The random walk recommenders on the bipartite user-item graph.
+// P3alpha (Cooper et al., 2014) scores item j from item i by the
+// probability of the 2-step walk i -> u -> j with the transition
+// probabilities raised to alpha:
+//
+//	W_ij = sum_u P(u|i)^alpha * P(j|u)^alpha
+//
+// RP3beta (Paudel et al., 2016) further divides W_ij by popularity(j)^beta
+// to promote the long-tail items. A user is recommended the items
+// reached from the user's history.
+type RandomWalk struct {
+	options RandomWalkOptions
+
+	userItems [][]math.SparseEntry
+
+	// The top-k neighbors of each item
+	neighbors [][]neighbor
+}
+
+func NewRandomWalk(options RandomWalkOptions) *RandomWalk {
+	m := new(RandomWalk)
+	m.options = options
+	return m
+}
+
+// Create the P3alpha recommender
+func NewP3Alpha(alpha float64, k int) *RandomWalk {
+	options := DefaultP3AlphaOptions()
+	options.Alpha = alpha
+	options.K = k
+	return NewRandomWalk(options)
+}
+
+// Create the RP3beta recommender
+func NewRP3Beta(alpha, beta float64, k int) *RandomWalk {
+	options := DefaultRP3BetaOptions()
+	options.Alpha = alpha
+	options.Beta = beta
+	options.K = k
+	return NewRandomWalk(options)
+}
+
+func (m *RandomWalk) Fit(records []*core.Record) error {
+	if len(records) == 0 {
+		return core.ErrorEmptyRecords
+	}
+	R, err := core.InteractionMatrix(records, m.options.Weights, 0, 0)
+	if err != nil {
+		return err
+	}
+	return m.Train(R)
+}
+
+// Train on the user x item interaction matrix
+func (m *RandomWalk) Train(R *math.SparseMatrix) error {
+	if R.Rows() == 0 || R.Cols() == 0 {
+		return core.ErrorEmptyRecords
+	}
+	o := m.options
+	m.userItems = R.RowEntries()
+	itemUsers := R.ColEntries()
+	if o.Binarize {
+		binarize(m.userItems)
+		binarize(itemUsers)
+	}
+
+	// P(j|u)^alpha and P(u|i)^alpha
+	userToItem := transitionPowers(m.userItems, o.Alpha)
+	itemToUser := transitionPowers(itemUsers, o.Alpha)
+
+	popularity := make([]float64, R.Cols())
+	for j, users := range itemUsers {
+		popularity[j] = float64(len(users))
+	}
+
+	numItems := R.Cols()
+	m.neighbors = make([][]neighbor, numItems)
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < runtime.NumCPU(); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			row := make([]float64, numItems)
+			for i := range jobs {
+				m.neighbors[i] = m.walk(i, itemToUser, userToItem, popularity, row)
+			}
+		}()
+	}
+	for i := 0; i < numItems; i++ {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return nil
+}
+
+// Compute the neighbors of item i, row is the zeroed accumulator
+func (m *RandomWalk) walk(i int, itemToUser, userToItem [][]math.SparseEntry, popularity, row []float64) []neighbor {
+	o := m.options
+	var touched []int
+	for _, u := range itemToUser[i] {
+		for _, j := range userToItem[u.Index] {
+			if row[j.Index] == 0 {
+				touched = append(touched, j.Index)
+			}
+			row[j.Index] += u.Value * j.Value
+		}
+	}
+	var list []neighbor
+	for _, j := range touched {
+		score := row[j]
+		row[j] = 0
+		if j == i {
+			continue
+		}
+		if o.Beta != 0 && popularity[j] > 0 {
+			score /= gomath.Pow(popularity[j], o.Beta)
+		}
+		list = append(list, neighbor{j, score})
+	}
+	sort.Slice(list, func(a, b int) bool {
+		if list[a].score != list[b].score {
+			return list[a].score > list[b].score
+		}
+		return list[a].item < list[b].item
+	})
+	if o.K > 0 && len(list) > o.K {
+		list = list[:o.K]
+	}
+	if o.NormalizeRows {
+		var s float64
+		for _, n := range list {
+			s += n.score
+		}
+		for k := range list {
+			list[k].score /= s
+		}
+	}
+	return list
+}
+
+func binarize(vectors [][]math.SparseEntry) {
+	for _, v := range vectors {
+		for k := range v {
+			v[k].Value = 1
+		}
+	}
+}
+
+// Normalize each vector into the transition probabilities, raised to alpha
+func transitionPowers(vectors [][]math.SparseEntry, alpha float64) [][]math.SparseEntry {
+	result := make([][]math.SparseEntry, len(vectors))
+	for i, v := range vectors {
+		var s float64
+		for _, e := range v {
+			s += e.Value
+		}
+		result[i] = make([]math.SparseEntry, len(v))
+		for k, e := range v {
+			result[i][k] = math.SparseEntry{Index: e.Index, Value: gomath.Pow(e.Value/s, alpha)}
+		}
+	}
+	return result
+}
+
+// Get the transition score from item i to item j, 0 if j is not a neighbor of i
+func (m *RandomWalk) Similarity(i, j int) float64 {
+	if i < 0 || i >= len(m.neighbors) {
+		return 0
+	}
+	for _, n := range m.neighbors[i] {
+		if n.item == j {
+			return n.score
+		}
+	}
+	return 0
+}
+
+// Score the items reached from the history of the user
+func (m *RandomWalk) Scores(userId int) map[int]float64 {
+	if userId < 0 || userId >= len(m.userItems) {
+		return nil
+	}
+	scores := make(map[int]float64)
+	for _, e := range m.userItems[userId] {
+		for _, n := range m.neighbors[e.Index] {
+			scores[n.item] += e.Value * n.score
+		}
+	}
+	for _, e := range m.userItems[userId] {
+		delete(scores, e.Index)
+	}
+	return scores
+}
+
+func (m *RandomWalk) Recommend(userId int, context *core.Context, n int) []*core.ScoredProduct {
+	return core.TopN(m.Scores(userId), n)
+}
@@ -0,0 +1,79 @@
+// Copyright (c) 2014 Feng Wang <wffrank1987@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language
+
+package graph
+
+import (
+	"github.com/numb3r3/gorec/core"
+	"github.com/numb3r3/gorec/utils"
+	"testing"
+	"time"
+)
+
+// user 0: 0 1, user 1: 0 1 2, user 2: 2, user 3: 1
+func records() []*core.Record {
+	var records []*core.Record
+	for u, items := range [][]int{{0, 1}, {0, 1, 2}, {2}, {1}} {
+		for _, i := range items {
+			records = append(records, core.NewEvent(u, i, core.EventView, time.Time{}))
+		}
+	}
+	return records
+}
+
+func TestP3Alpha(t *testing.T) {
+	model := NewP3Alpha(1, 0)
+	if err := model.Fit(records()); err != nil {
+		t.Fatal(err)
+	}
+	// W_01 = P(u0|0) P(1|u0) + P(u1|0) P(1|u1) = 1/2 * 1/2 + 1/2 * 1/3
+	utils.ExpectNear(t, 5.0/12, model.Similarity(0, 1), 1e-12)
+	// W_10 = P(u0|1) P(0|u0) + P(u1|1) P(0|u1) = 1/3 * 1/2 + 1/3 * 1/3
+	utils.ExpectNear(t, 5.0/18, model.Similarity(1, 0), 1e-12)
+	// W_12 = P(u1|1) P(2|u1) = 1/3 * 1/3
+	utils.ExpectNear(t, 1.0/9, model.Similarity(1, 2), 1e-12)
+	utils.ExpectNear(t, 0, model.Similarity(0, 0), 1e-12)
+
+	// user 2 reaches the items 0 and 1 with the same W_20 = W_21 = 1/2 * 1/3
+	scores := model.Scores(2)
+	utils.Expect(t, "2", len(scores))
+	utils.ExpectNear(t, 1.0/6, scores[0], 1e-12)
+	utils.ExpectNear(t, 1.0/6, scores[1], 1e-12)
+
+	model = NewP3Alpha(2, 0)
+	model.Fit(records())
+	utils.ExpectNear(t, 1.0/16+1.0/36, model.Similarity(0, 1), 1e-12)
+}
+
+func TestRP3Beta(t *testing.T) {
+	model := NewRP3Beta(1, 1, 0)
+	if err := model.Fit(records()); err != nil {
+		t.Fatal(err)
+	}
+	// the popularity of the items 0 and 1 is 2 and 3
+	utils.ExpectNear(t, 5.0/12/3, model.Similarity(0, 1), 1e-12)
+	utils.ExpectNear(t, 5.0/18/2, model.Similarity(1, 0), 1e-12)
+
+	// the less popular item 0 breaks the tie for user 2
+	scores := model.Scores(2)
+	utils.ExpectNear(t, 1.0/6/2, scores[0], 1e-12)
+	utils.ExpectNear(t, 1.0/6/3, scores[1], 1e-12)
+	result := model.Recommend(2, nil, 1)
+	utils.Expect(t, "0", result[0].ProductId)
+
+	// only the best neighbor is kept with K = 1
+	model = NewRP3Beta(1, 1, 1)
+	model.Fit(records())
+	utils.ExpectNear(t, 5.0/12/3, model.Similarity(0, 1), 1e-12)
+	utils.ExpectNear(t, 0, model.Similarity(0, 2), 1e-12)
+}
@@ -0,0 +1,200 @@
+// Copyright (c) 2014 Feng Wang <wffrank1987@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language
+
+package hybrid
+
+import (
+	"sort"
+
+	"github.com/numb3r3/gorec/core"
+)
+
+// The options of the weighted blending
+type BlendOptions struct {
+	Normalization Normalization
+
+	// The number of candidates requested from every member
+	Depth int
+
+	// The latest fraction of the records of every user held out to learn
+	// the member weights, 0 to keep the given weights
+	ValidationFraction float64
+
+	// The cutoff of the recall maximized on the validation records
+	ValidationN int
+
+	// The weights tried for every member by the coordinate ascent
+	WeightGrid []float64
+
+	// The maximum number of the coordinate ascent sweeps
+	NumIterations int
+}
+
+func DefaultBlendOptions() BlendOptions {
+	return BlendOptions{
+		Normalization:      NormalizeMinMax,
+		Depth:              100,
+		ValidationFraction: 0.2,
+		ValidationN:        10,
+		WeightGrid:         []float64{0, 0.25, 0.5, 0.75, 1},
+		NumIterations:      5,
+	}
+}
+
+// A hybrid recommender scoring a product by the weighted sum of its
+// normalized scores of the members. A product missing from the candidates
+// of a member gets no score from it.
+type Blend struct {
+	options BlendOptions
+
+	members []*Member
+}
+
+func NewBlend(options BlendOptions, members ...*Member) *Blend {
+	b := new(Blend)
+	b.options = options
+	b.members = members
+	return b
+}
+
+// Get the members with their current weights
+func (b *Blend) Members() []*Member {
+	return b.members
+}
+
+// Fit the members. If ValidationFraction is positive, the members are first
+// fitted without the latest records of the users to learn their weights on
+// them, and then refitted on all of the records.
+func (b *Blend) Fit(records []*core.Record) error {
+	if len(records) == 0 {
+		return core.ErrorEmptyRecords
+	}
+	if b.options.ValidationFraction > 0 {
+		train, validation := splitLatest(records, b.options.ValidationFraction)
+		if len(validation) > 0 {
+			if err := fitMembers(b.members, train); err != nil {
+				return err
+			}
+			b.LearnWeights(validation)
+		}
+	}
+	return fitMembers(b.members, records)
+}
+
+// Learn the member weights maximizing the recall of the fitted members on
+// the validation records by coordinate ascent over the weight grid, and
+// return the reached recall. The weights are kept and 0 is returned if
+// there is no validation record or ValidationN is not positive.
+func (b *Blend) LearnWeights(validation []*core.Record) float64 {
+	if len(validation) == 0 || b.options.ValidationN <= 0 {
+		return 0
+	}
+	relevant := make(map[int]map[int]bool)
+	for _, r := range validation {
+		if relevant[r.UserId] == nil {
+			relevant[r.UserId] = make(map[int]bool)
+		}
+		relevant[r.UserId][r.ProductId] = true
+	}
+
+	// the normalized candidates of every member for every user, in the
+	// order of the user ids so that the recall sums are reproducible
+	users := make([]int, 0, len(relevant))
+	for u := range relevant {
+		users = append(users, u)
+	}
+	sort.Ints(users)
+	candidates := make([][]map[int]float64, len(users))
+	for k, u := range users {
+		candidates[k] = b.candidates(u, nil)
+	}
+
+	weights := make([]float64, len(b.members))
+	for i, m := range b.members {
+		weights[i] = m.Weight
+	}
+	recall := func() float64 {
+		var sum float64
+		for k, u := range users {
+			top := core.TopN(combine(candidates[k], weights), b.options.ValidationN)
+			var hits int
+			for _, p := range top {
+				if relevant[u][p.ProductId] {
+					hits++
+				}
+			}
+			expected := len(relevant[u])
+			if expected > b.options.ValidationN {
+				expected = b.options.ValidationN
+			}
+			sum += float64(hits) / float64(expected)
+		}
+		return sum / float64(len(users))
+	}
+
+	best := recall()
+	for iter := 0; iter < b.options.NumIterations; iter++ {
+		improved := false
+		for i := range weights {
+			current := weights[i]
+			for _, w := range b.options.WeightGrid {
+				if w == current {
+					continue
+				}
+				weights[i] = w
+				if score := recall(); score > best {
+					best, current, improved = score, w, true
+				}
+			}
+			weights[i] = current
+		}
+		if !improved {
+			break
+		}
+	}
+	for i, m := range b.members {
+		m.Weight = weights[i]
+	}
+	return best
+}
+
+// Get the normalized candidates of every member
+func (b *Blend) candidates(userId int, context *core.Context) []map[int]float64 {
+	scores := make([]map[int]float64, len(b.members))
+	for i, m := range b.members {
+		products := m.Recommender.Recommend(userId, context, b.options.Depth)
+		scores[i] = Normalize(products, b.options.Normalization)
+	}
+	return scores
+}
+
+func combine(scores []map[int]float64, weights []float64) map[int]float64 {
+	combined := make(map[int]float64)
+	for i, s := range scores {
+		if weights[i] == 0 {
+			continue
+		}
+		for id, score := range s {
+			combined[id] += weights[i] * score
+		}
+	}
+	return combined
+}
+
+func (b *Blend) Recommend(userId int, context *core.Context, n int) []*core.ScoredProduct {
+	weights := make([]float64, len(b.members))
+	for i, m := range b.members {
+		weights[i] = m.Weight
+	}
+	return core.TopN(combine(b.candidates(userId, context), weights), n)
+}
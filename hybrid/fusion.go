@@ -0,0 +1,86 @@
+// Copyright (c) 2014 Feng Wang <wffrank1987@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language
+
+package hybrid
+
+import (
+	"github.com/numb3r3/gorec/core"
+)
+
+// The rank fusion methods
+type FusionMethod int
+
+const (
+	// Score a product by the sum of weight / (K + rank) over the members
+	ReciprocalRank FusionMethod = iota
+
+	// Score a product by the sum of weight * (Depth - rank) over the members
+	Borda
+)
+
+// The options of the rank fusion
+type FusionOptions struct {
+	Method FusionMethod
+
+	// The rank offset of the reciprocal rank fusion
+	K float64
+
+	// The number of candidates requested from every member
+	Depth int
+}
+
+func DefaultFusionOptions() FusionOptions {
+	return FusionOptions{Method: ReciprocalRank, K: 60, Depth: 100}
+}
+
+// A hybrid recommender combining the ranks instead of the scores of the
+// members, which needs no normalization of incomparable scores
+type Fusion struct {
+	options FusionOptions
+
+	members []*Member
+}
+
+func NewFusion(options FusionOptions, members ...*Member) *Fusion {
+	f := new(Fusion)
+	f.options = options
+	f.members = members
+	return f
+}
+
+func (f *Fusion) Members() []*Member {
+	return f.members
+}
+
+func (f *Fusion) Fit(records []*core.Record) error {
+	if len(records) == 0 {
+		return core.ErrorEmptyRecords
+	}
+	return fitMembers(f.members, records)
+}
+
+func (f *Fusion) Recommend(userId int, context *core.Context, n int) []*core.ScoredProduct {
+	scores := make(map[int]float64)
+	for _, m := range f.members {
+		ranked := rankOrder(m.Recommender.Recommend(userId, context, f.options.Depth))
+		for rank, p := range ranked {
+			switch f.options.Method {
+			case Borda:
+				scores[p.ProductId] += m.Weight * float64(f.options.Depth-rank)
+			default:
+				scores[p.ProductId] += m.Weight / (f.options.K + float64(rank+1))
+			}
+		}
+	}
+	return core.TopN(scores, n)
+}
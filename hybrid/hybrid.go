@@ -0,0 +1,141 @@
+// Copyright (c) 2014 Feng Wang <wffrank1987@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language
+
+package hybrid
+
+import (
+	gomath "math"
+	"sort"
+
+	"github.com/numb3r3/gorec/core"
+)
+
+// A recommender of a hybrid with its weight
+type Member struct {
+	Name        string
+	Recommender core.Recommender
+	Weight      float64
+}
+
+func NewMember(name string, recommender core.Recommender, weight float64) *Member {
+	return &Member{Name: name, Recommender: recommender, Weight: weight}
+}
+
+// The normalization of the scores of a member before they are combined
+type Normalization int
+
+const (
+	// Keep the raw scores
+	NormalizeNone Normalization = iota
+
+	// Scale the scores into [0, 1]
+	NormalizeMinMax
+
+	// Center the scores and divide them by their standard deviation
+	NormalizeZScore
+
+	// Replace the scores by 1 - rank / length, 1 for the first product
+	NormalizeRank
+)
+
+// Normalize the scores of the products of a recommendation list
+func Normalize(products []*core.ScoredProduct, normalization Normalization) map[int]float64 {
+	scores := make(map[int]float64, len(products))
+	if len(products) == 0 {
+		return scores
+	}
+	switch normalization {
+	case NormalizeMinMax:
+		min, max := gomath.Inf(1), gomath.Inf(-1)
+		for _, p := range products {
+			min, max = gomath.Min(min, p.Score), gomath.Max(max, p.Score)
+		}
+		for _, p := range products {
+			if max > min {
+				scores[p.ProductId] = (p.Score - min) / (max - min)
+			} else {
+				scores[p.ProductId] = 1
+			}
+		}
+	case NormalizeZScore:
+		var mean, variance float64
+		for _, p := range products {
+			mean += p.Score
+		}
+		mean /= float64(len(products))
+		for _, p := range products {
+			variance += (p.Score - mean) * (p.Score - mean)
+		}
+		std := gomath.Sqrt(variance / float64(len(products)))
+		for _, p := range products {
+			if std > 0 {
+				scores[p.ProductId] = (p.Score - mean) / std
+			} else {
+				scores[p.ProductId] = 0
+			}
+		}
+	case NormalizeRank:
+		ranked := rankOrder(products)
+		for i, p := range ranked {
+			scores[p.ProductId] = 1 - float64(i)/float64(len(ranked))
+		}
+	default:
+		for _, p := range products {
+			scores[p.ProductId] = p.Score
+		}
+	}
+	return scores
+}
+
+// Get the products sorted by descending score, without modifying the input
+func rankOrder(products []*core.ScoredProduct) []*core.ScoredProduct {
+	ranked := make([]*core.ScoredProduct, len(products))
+	copy(ranked, products)
+	core.SortScoredProducts(ranked)
+	return ranked
+}
+
+// Fit every member on the records
+func fitMembers(members []*Member, records []*core.Record) error {
+	for _, m := range members {
+		if err := m.Recommender.Fit(records); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Split off the latest fraction of the records of every user for validation
+func splitLatest(records []*core.Record, fraction float64) (train, validation []*core.Record) {
+	byUser := make(map[int][]*core.Record)
+	for _, r := range records {
+		byUser[r.UserId] = append(byUser[r.UserId], r)
+	}
+	users := make([]int, 0, len(byUser))
+	for u := range byUser {
+		users = append(users, u)
+	}
+	sort.Ints(users)
+	for _, u := range users {
+		rs := byUser[u]
+		core.SortRecordsByTime(rs)
+		held := int(gomath.Floor(float64(len(rs)) * fraction))
+		// keep at least one record of the user for training
+		if held >= len(rs) {
+			held = len(rs) - 1
+		}
+		train = append(train, rs[:len(rs)-held]...)
+		validation = append(validation, rs[len(rs)-held:]...)
+	}
+	return
+}
@@ -0,0 +1,101 @@
+// Copyright (c) 2014 Feng Wang <wffrank1987@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language
+
+package hybrid
+
+import (
+	"fmt"
+	"github.com/numb3r3/gorec/core"
+	"github.com/numb3r3/gorec/utils"
+	"testing"
+	"time"
+)
+
+// A recommender returning a fixed list to every user
+type fixed []*core.ScoredProduct
+
+func (f fixed) Fit(records []*core.Record) error { return nil }
+
+func (f fixed) Recommend(userId int, context *core.Context, n int) []*core.ScoredProduct {
+	if n < len(f) {
+		return f[:n]
+	}
+	return f
+}
+
+func list(ids ...int) fixed {
+	products := make(fixed, len(ids))
+	for i, id := range ids {
+		products[i] = &core.ScoredProduct{ProductId: id, Score: float64(len(ids) - i)}
+	}
+	return products
+}
+
+func ids(products []*core.ScoredProduct) string {
+	s := ""
+	for _, p := range products {
+		s += fmt.Sprint(p.ProductId, " ")
+	}
+	return s
+}
+
+func TestNormalize(t *testing.T) {
+	scores := Normalize(list(3, 1, 2), NormalizeMinMax)
+	utils.Expect(t, "1 0.5 0", fmt.Sprint(scores[3], scores[1], scores[2]))
+	scores = Normalize(list(3, 1), NormalizeRank)
+	utils.Expect(t, "1 0.5", fmt.Sprint(scores[3], scores[1]))
+}
+
+func TestFusion(t *testing.T) {
+	a := NewMember("a", list(1, 2, 3), 1)
+	b := NewMember("b", list(2, 3, 4), 1)
+	f := NewFusion(DefaultFusionOptions(), a, b)
+	utils.Expect(t, "2 3 1 4 ", ids(f.Recommend(0, nil, 4)))
+}
+
+func TestLearnWeights(t *testing.T) {
+	good := NewMember("good", list(1, 2), 1)
+	bad := NewMember("bad", list(3, 4, 5), 1)
+	options := DefaultBlendOptions()
+	options.ValidationN = 2
+	b := NewBlend(options, good, bad)
+	now := time.Now()
+	validation := []*core.Record{
+		core.NewEvent(0, 1, core.EventView, now),
+		core.NewEvent(0, 2, core.EventView, now),
+	}
+	utils.ExpectNear(t, 1, b.LearnWeights(validation), 1e-9)
+	utils.Expect(t, "1 0", fmt.Sprint(good.Weight, bad.Weight))
+
+	// the weights are kept without validation records or cutoff
+	good.Weight, bad.Weight = 0.5, 0.5
+	utils.Expect(t, "0", b.LearnWeights(nil))
+	utils.Expect(t, "0.5 0.5", fmt.Sprint(good.Weight, bad.Weight))
+	options.ValidationN = 0
+	b = NewBlend(options, good, bad)
+	utils.Expect(t, "0", b.LearnWeights(validation))
+	utils.Expect(t, "0.5 0.5", fmt.Sprint(good.Weight, bad.Weight))
+}
+
+func TestSwitching(t *testing.T) {
+	s := NewSwitching(list(1)).When(ColdUser(2), list(2))
+	now := time.Now()
+	s.Fit([]*core.Record{
+		core.NewEvent(0, 1, core.EventView, now),
+		core.NewEvent(0, 2, core.EventView, now),
+		core.NewEvent(1, 1, core.EventView, now),
+	})
+	utils.Expect(t, "1 ", ids(s.Recommend(0, nil, 1)))
+	utils.Expect(t, "2 ", ids(s.Recommend(1, nil, 1)))
+	utils.Expect(t, "2 ", ids(s.Recommend(7, nil, 1)))
+}
@@ -0,0 +1,94 @@
+// Copyright (c) 2014 Feng Wang <wffrank1987@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language
+
+package hybrid
+
+import (
+	"github.com/numb3r3/gorec/core"
+)
+
+// A condition on the user to select a recommender, history is nil for
+// the users without records
+type Condition func(userId int, history *core.UserHistory, context *core.Context) bool
+
+// Hold for the users with less than minInteractions records
+func ColdUser(minInteractions int) Condition {
+	return func(userId int, history *core.UserHistory, context *core.Context) bool {
+		return history == nil || history.NumInteractions() < minInteractions
+	}
+}
+
+// Hold for the contexts in the segment of the segmenter
+func InSegment(segmenter core.ContextSegmenter, segment string) Condition {
+	return func(userId int, history *core.UserHistory, context *core.Context) bool {
+		return context != nil && segmenter(context) == segment
+	}
+}
+
+type branch struct {
+	condition   Condition
+	recommender core.Recommender
+}
+
+// A hybrid recommender delegating to the recommender of the first holding
+// condition, or to the default recommender if none holds
+type Switching struct {
+	branches []branch
+
+	fallback core.Recommender
+
+	history *core.HistoryIndex
+}
+
+func NewSwitching(fallback core.Recommender) *Switching {
+	s := new(Switching)
+	s.fallback = fallback
+	s.history = core.NewHistoryIndex()
+	return s
+}
+
+// Add a recommender for the users satisfying the condition, the earlier
+// added conditions are checked first
+func (s *Switching) When(condition Condition, recommender core.Recommender) *Switching {
+	s.branches = append(s.branches, branch{condition, recommender})
+	return s
+}
+
+// Fit every recommender on all of the records
+func (s *Switching) Fit(records []*core.Record) error {
+	if len(records) == 0 {
+		return core.ErrorEmptyRecords
+	}
+	s.history = core.BuildHistoryIndex(records)
+	for _, b := range s.branches {
+		if err := b.recommender.Fit(records); err != nil {
+			return err
+		}
+	}
+	return s.fallback.Fit(records)
+}
+
+// Get the recommender selected for the user in the context
+func (s *Switching) Select(userId int, context *core.Context) core.Recommender {
+	history := s.history.GetHistory(userId)
+	for _, b := range s.branches {
+		if b.condition(userId, history, context) {
+			return b.recommender
+		}
+	}
+	return s.fallback
+}
+
+func (s *Switching) Recommend(userId int, context *core.Context, n int) []*core.ScoredProduct {
+	return s.Select(userId, context).Recommend(userId, context, n)
+}
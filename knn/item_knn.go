@@ -0,0 +1,159 @@
+// Copyright (c) 2014 Feng Wang <wffrank1987@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language
+
+package knn
+
+import (
+	gomath "math"
+
+	"github.com/numb3r3/gorec/core"
+	"github.com/numb3r3/gorec/math"
+)
+
+// The options of the item-based collaborative filtering
+type ItemKNNOptions struct {
+
+	// The similarity measure between the items
+	Similarity Similarity
+
+	// The number of neighbors kept per item, unlimited if not positive
+	K int
+
+	// Shrink the similarity of the items co-rated by few users
+	Shrinkage float64
+
+	// Whether to fit on the explicit ratings instead of the implicit events
+	UseRatings bool
+
+	// The weights of the implicit events, nil for the default weights
+	Weights core.EventWeights
+
+	// The candidate neighbors of an item, e.g. from a LSH index over the
+	// item columns. All of the co-occurring items are compared if nil.
+	Candidates func(item int) []int
+}
+
+func DefaultItemKNNOptions() ItemKNNOptions {
+	return ItemKNNOptions{Similarity: Cosine, K: 50}
+}
+
+// The item-based k-nearest-neighbors recommender. The score of an item for
+// a user is the similarity-weighted sum of the user's values on the items
+// whose neighborhood contains it.
+type ItemKNN struct {
+	options ItemKNNOptions
+
+	// The items of each user
+	userItems [][]math.SparseEntry
+
+	// The mean value of each user
+	userMeans []float64
+
+	// The top-k neighbors of each item
+	neighbors [][]Neighbor
+}
+
+func NewItemKNN(options ItemKNNOptions) *ItemKNN {
+	model := new(ItemKNN)
+	model.options = options
+	return model
+}
+
+func (m *ItemKNN) Fit(records []*core.Record) error {
+	if len(records) == 0 {
+		return core.ErrorEmptyRecords
+	}
+	var R *math.SparseMatrix
+	var err error
+	if m.options.UseRatings {
+		R, err = core.RatingMatrix(records, 0, 0)
+	} else {
+		R, err = core.InteractionMatrix(records, m.options.Weights, 0, 0)
+	}
+	if err != nil {
+		return err
+	}
+	return m.Train(R)
+}
+
+// Train the model on the user x item matrix
+func (m *ItemKNN) Train(R *math.SparseMatrix) error {
+	if R.Rows() == 0 || R.Cols() == 0 {
+		return core.ErrorEmptyRecords
+	}
+	m.userItems = R.RowEntries()
+	m.userMeans = entryMeans(m.userItems)
+	m.neighbors = computeNeighbors(R.ColEntries(), m.userItems, m.userMeans,
+		m.options.Similarity, m.options.Shrinkage, m.options.K, 0, m.options.Candidates)
+	return nil
+}
+
+// Get the neighbors of the item ordered by descending similarity
+func (m *ItemKNN) Neighbors(item int) []Neighbor {
+	if item < 0 || item >= len(m.neighbors) {
+		return nil
+	}
+	return m.neighbors[item]
+}
+
+// Get the similarity between the items, 0 if j is not a neighbor of i
+func (m *ItemKNN) Similarity(i, j int) float64 {
+	for _, n := range m.Neighbors(i) {
+		if n.Index == j {
+			return n.Similarity
+		}
+	}
+	return 0
+}
+
+// Score all of the items for the user from the user's history
+func (m *ItemKNN) Scores(userId int) map[int]float64 {
+	if userId < 0 || userId >= len(m.userItems) {
+		return nil
+	}
+	scores := make(map[int]float64)
+	for _, e := range m.userItems[userId] {
+		for _, n := range m.neighbors[e.Index] {
+			scores[n.Index] += n.Similarity * e.Value
+		}
+	}
+	for _, e := range m.userItems[userId] {
+		delete(scores, e.Index)
+	}
+	return scores
+}
+
+func (m *ItemKNN) Recommend(userId int, context *core.Context, n int) []*core.ScoredProduct {
+	return core.TopN(m.Scores(userId), n)
+}
+
+// Predict the value of the user on the item by the weighted average of
+// the user's values on the neighbors of the item. The user mean is
+// returned if the user has no value on any neighbor.
+func (m *ItemKNN) Predict(userId, item int) float64 {
+	if userId < 0 || userId >= len(m.userItems) {
+		return 0
+	}
+	items := m.userItems[userId]
+	var sum, weights float64
+	for _, n := range m.Neighbors(item) {
+		if v, ok := math.LookupEntry(items, n.Index); ok {
+			sum += n.Similarity * v
+			weights += gomath.Abs(n.Similarity)
+		}
+	}
+	if weights == 0 {
+		return m.userMeans[userId]
+	}
+	return sum / weights
+}
@@ -0,0 +1,161 @@
+// Copyright (c) 2014 Feng Wang <wffrank1987@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language
+
+package knn
+
+import (
+	"fmt"
+	gomath "math"
+	"github.com/numb3r3/gorec/math"
+	"github.com/numb3r3/gorec/utils"
+	"testing"
+)
+
+func ratings() *math.SparseMatrix {
+	return math.MakeSparseCopy(math.MakeDenseMatrixStacked([][]float64{
+		[]float64{5, 4, 0, 1},
+		[]float64{4, 5, 1, 0},
+		[]float64{1, 0, 5, 4},
+		[]float64{0, 1, 4, 5},
+		[]float64{5, 0, 0, 0},
+	}))
+}
+
+// Make the sparse vector of the non-zero values
+func entries(values ...float64) []math.SparseEntry {
+	var result []math.SparseEntry
+	for i, v := range values {
+		if v != 0 {
+			result = append(result, math.SparseEntry{Index: i, Value: v})
+		}
+	}
+	return result
+}
+
+func TestSimilarity(t *testing.T) {
+	a := entries(1, 1, 1, 0)
+	b := entries(0, 1, 1, 1)
+	utils.Expect(t, "0.5", ComputeSimilarity(Jaccard, a, b, nil, 0))
+	utils.ExpectNear(t, 2.0/3, ComputeSimilarity(Cosine, a, b, nil, 0), 1e-9)
+	utils.ExpectNear(t, 1.0/3, ComputeSimilarity(Cosine, a, b, nil, 2), 1e-9)
+
+	c := entries(1, 2, 3)
+	d := entries(2, 4, 6)
+	utils.ExpectNear(t, 1, ComputeSimilarity(Pearson, c, d, nil, 0), 1e-9)
+}
+
+func TestItemKNN(t *testing.T) {
+	options := DefaultItemKNNOptions()
+	options.K = 2
+	model := NewItemKNN(options)
+	if err := model.Train(ratings()); err != nil {
+		t.Fatal(err)
+	}
+	utils.Expect(t, "1", model.Neighbors(0)[0].Index)
+	utils.Expect(t, "3", model.Neighbors(2)[0].Index)
+
+	top := model.Recommend(4, nil, 1)
+	utils.Expect(t, "1", top[0].ProductId)
+	utils.ExpectNear(t, 5, model.Predict(4, 1), 1e-9)
+}
+
+func TestItemKNNCandidates(t *testing.T) {
+	options := DefaultItemKNNOptions()
+	// only the opposite item is compared, the others returned by the
+	// hook are out of range or the item itself
+	options.Candidates = func(item int) []int {
+		return []int{(item + 2) % 4, item, -1, 9}
+	}
+	model := NewItemKNN(options)
+	if err := model.Train(ratings()); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 4; i++ {
+		neighbors := model.Neighbors(i)
+		utils.Expect(t, "1", len(neighbors))
+		utils.Expect(t, fmt.Sprint((i+2)%4), neighbors[0].Index)
+	}
+	utils.Expect(t, "0", model.Similarity(0, 1))
+}
+
+func TestUserKNN(t *testing.T) {
+	options := DefaultUserKNNOptions()
+	options.Aggregation = MeanCentered
+	model := NewUserKNN(options)
+	if err := model.Train(ratings()); err != nil {
+		t.Fatal(err)
+	}
+	utils.Expect(t, "1", model.Neighbors(0)[0].Index)
+
+	// user 0 is closest to user 1, who dislikes item 2
+	utils.Expect(t, "true", model.Predict(0, 2) < 10.0/3)
+	top := model.Recommend(0, nil, 5)
+	utils.Expect(t, "1", len(top))
+	utils.Expect(t, "2", top[0].ProductId)
+}
+
+// By Jaccard, user 0 is similar to user 1 by 2/3 and to user 2 by 1/4
+func smallRatings() *math.SparseMatrix {
+	return math.MakeSparseCopy(math.MakeDenseMatrixStacked([][]float64{
+		[]float64{5, 1, 0, 0},
+		[]float64{5, 3, 4, 0},
+		[]float64{0, 1, 3, 2},
+		[]float64{0, 0, 0, 5},
+	}))
+}
+
+func TestUserKNNAggregations(t *testing.T) {
+	options := DefaultUserKNNOptions()
+	options.Similarity = Jaccard
+	model := NewUserKNN(options)
+	if err := model.Train(smallRatings()); err != nil {
+		t.Fatal(err)
+	}
+	utils.Expect(t, "2", len(model.Neighbors(0)))
+	utils.ExpectNear(t, 2.0/3, model.Neighbors(0)[0].Similarity, 1e-12)
+	utils.ExpectNear(t, 0.25, model.Neighbors(0)[1].Similarity, 1e-12)
+
+	// WeightedSum ranks by 2/3 * 4 + 1/4 * 3 and 1/4 * 2,
+	// and predicts the weighted average over 2/3 + 1/4 = 11/12
+	scores := model.Scores(0)
+	utils.Expect(t, "2", len(scores))
+	utils.ExpectNear(t, 41.0/12, scores[2], 1e-12)
+	utils.ExpectNear(t, 0.5, scores[3], 1e-12)
+	utils.ExpectNear(t, 41.0/11, model.Predict(0, 2), 1e-12)
+	top := model.Recommend(0, nil, 2)
+	utils.Expect(t, "2", top[0].ProductId)
+	utils.Expect(t, "3", top[1].ProductId)
+
+	// the user means are 3, 4 and 2, only user 2 deviates on item 2 by 1
+	options.Aggregation = MeanCentered
+	model = NewUserKNN(options)
+	model.Train(smallRatings())
+	utils.ExpectNear(t, 3+(0.25*1)/(11.0/12), model.Predict(0, 2), 1e-12)
+
+	// the standard deviations of the users 0, 1 and 2 are 2, sqrt(2/3) and sqrt(2/3)
+	options.Aggregation = ZScore
+	model = NewUserKNN(options)
+	model.Train(smallRatings())
+	utils.ExpectNear(t, 3+2*(0.25/gomath.Sqrt(2.0/3))/(11.0/12), model.Predict(0, 2), 1e-12)
+
+	// only user 1 is above the threshold, nobody else rated item 3
+	options.Aggregation = WeightedSum
+	options.Threshold = 0.5
+	model = NewUserKNN(options)
+	model.Train(smallRatings())
+	utils.Expect(t, "1", len(model.Neighbors(0)))
+	scores = model.Scores(0)
+	utils.Expect(t, "1", len(scores))
+	utils.ExpectNear(t, 8.0/3, scores[2], 1e-12)
+	utils.ExpectNear(t, 3, model.Predict(0, 3), 1e-12)
+}
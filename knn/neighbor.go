@@ -0,0 +1,94 @@
+// Copyright (c) 2014 Feng Wang <wffrank1987@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language
+
+package knn
+
+import (
+	"runtime"
+	"sort"
+	"sync"
+
+	"github.com/numb3r3/gorec/math"
+)
+
+// A neighbor of a user or an item
+type Neighbor struct {
+	Index int
+
+	Similarity float64
+}
+
+// Sort the neighbors by descending similarity, and ascending index for ties
+func sortNeighbors(neighbors []Neighbor) {
+	sort.Slice(neighbors, func(a, b int) bool {
+		if neighbors[a].Similarity != neighbors[b].Similarity {
+			return neighbors[a].Similarity > neighbors[b].Similarity
+		}
+		return neighbors[a].Index < neighbors[b].Index
+	})
+}
+
+// Compute the neighbors of each vector. The candidates of vector i are the
+// vectors sharing at least one non-zero index with it, found through the
+// transposed vectors, or given by candidatesOf if it is not nil. The neighbors are the candidates with similarity
+// above minSimilarity, at most k (unlimited if k <= 0) of them are kept.
+func computeNeighbors(vectors, transposed [][]math.SparseEntry, means []float64,
+	kind Similarity, shrinkage float64, k int, minSimilarity float64, candidatesOf func(i int) []int) [][]Neighbor {
+
+	neighbors := make([][]Neighbor, len(vectors))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < runtime.NumCPU(); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			candidates := make(map[int]bool)
+			for i := range jobs {
+				if candidatesOf != nil {
+					for _, j := range candidatesOf(i) {
+						if j != i && j >= 0 && j < len(vectors) {
+							candidates[j] = true
+						}
+					}
+				} else {
+					for _, e := range vectors[i] {
+						for _, t := range transposed[e.Index] {
+							if t.Index != i {
+								candidates[t.Index] = true
+							}
+						}
+					}
+				}
+				var list []Neighbor
+				for j := range candidates {
+					sim := ComputeSimilarity(kind, vectors[i], vectors[j], means, shrinkage)
+					if sim > minSimilarity {
+						list = append(list, Neighbor{j, sim})
+					}
+					delete(candidates, j)
+				}
+				sortNeighbors(list)
+				if k > 0 && len(list) > k {
+					list = list[:k]
+				}
+				neighbors[i] = list
+			}
+		}()
+	}
+	for i := range vectors {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return neighbors
+}
@@ -0,0 +1,179 @@
+// Copyright (c) 2014 Feng Wang <wffrank1987@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language
+
+package knn
+
+import (
+	gomath "math"
+
+	"github.com/numb3r3/gorec/math"
+)
+
+// The measure of similarity between two sparse vectors
+type Similarity int
+
+const (
+	// The cosine of the angle between the vectors
+	Cosine Similarity = iota
+	// The cosine of the vectors centered by the means of the other side
+	// (e.g. the user means when comparing two items)
+	AdjustedCosine
+	// The Pearson correlation over the co-rated elements
+	Pearson
+	// The size of the intersection over the size of the union
+	Jaccard
+)
+
+func (s Similarity) String() string {
+	switch s {
+	case Cosine:
+		return "cosine"
+	case AdjustedCosine:
+		return "adjusted-cosine"
+	case Pearson:
+		return "pearson"
+	case Jaccard:
+		return "jaccard"
+	}
+	return "unknown"
+}
+
+// Compute the similarity between the two sparse vectors ordered by index.
+// The means, indexed by the entry index, are only used by AdjustedCosine.
+// The similarity is shrunk by n / (n + shrinkage) with n co-rated elements.
+func ComputeSimilarity(kind Similarity, a, b []math.SparseEntry, means []float64, shrinkage float64) float64 {
+	var sim float64
+	var n int
+	switch kind {
+	case Cosine:
+		sim, n = cosine(a, b)
+	case AdjustedCosine:
+		sim, n = adjustedCosine(a, b, means)
+	case Pearson:
+		sim, n = pearson(a, b)
+	case Jaccard:
+		sim, n = jaccard(a, b)
+	}
+	if shrinkage > 0 && n > 0 {
+		sim *= float64(n) / (float64(n) + shrinkage)
+	}
+	return sim
+}
+
+// Call f on each pair of the elements with the same index
+func eachCommon(a, b []math.SparseEntry, f func(va, vb float64, index int)) {
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i].Index < b[j].Index:
+			i++
+		case a[i].Index > b[j].Index:
+			j++
+		default:
+			f(a[i].Value, b[j].Value, a[i].Index)
+			i++
+			j++
+		}
+	}
+}
+
+func cosine(a, b []math.SparseEntry) (float64, int) {
+	var dot float64
+	n := 0
+	eachCommon(a, b, func(va, vb float64, index int) {
+		dot += va * vb
+		n++
+	})
+	if n == 0 {
+		return 0, 0
+	}
+	na, nb := norm(a), norm(b)
+	if na == 0 || nb == 0 {
+		return 0, n
+	}
+	return dot / (na * nb), n
+}
+
+func adjustedCosine(a, b []math.SparseEntry, means []float64) (float64, int) {
+	var dot, sa, sb float64
+	n := 0
+	eachCommon(a, b, func(va, vb float64, index int) {
+		da, db := va-means[index], vb-means[index]
+		dot += da * db
+		sa += da * da
+		sb += db * db
+		n++
+	})
+	if sa == 0 || sb == 0 {
+		return 0, n
+	}
+	return dot / gomath.Sqrt(sa*sb), n
+}
+
+func pearson(a, b []math.SparseEntry) (float64, int) {
+	var suma, sumb float64
+	n := 0
+	eachCommon(a, b, func(va, vb float64, index int) {
+		suma += va
+		sumb += vb
+		n++
+	})
+	if n < 2 {
+		return 0, n
+	}
+	ma, mb := suma/float64(n), sumb/float64(n)
+	var dot, sa, sb float64
+	eachCommon(a, b, func(va, vb float64, index int) {
+		dot += (va - ma) * (vb - mb)
+		sa += (va - ma) * (va - ma)
+		sb += (vb - mb) * (vb - mb)
+	})
+	if sa == 0 || sb == 0 {
+		return 0, n
+	}
+	return dot / gomath.Sqrt(sa*sb), n
+}
+
+func jaccard(a, b []math.SparseEntry) (float64, int) {
+	n := 0
+	eachCommon(a, b, func(va, vb float64, index int) { n++ })
+	union := len(a) + len(b) - n
+	if union == 0 {
+		return 0, 0
+	}
+	return float64(n) / float64(union), n
+}
+
+func norm(a []math.SparseEntry) float64 {
+	var s float64
+	for _, e := range a {
+		s += e.Value * e.Value
+	}
+	return gomath.Sqrt(s)
+}
+
+// Get the mean of the non-zero elements of each vector
+func entryMeans(vectors [][]math.SparseEntry) []float64 {
+	means := make([]float64, len(vectors))
+	for i, v := range vectors {
+		if len(v) == 0 {
+			continue
+		}
+		var s float64
+		for _, e := range v {
+			s += e.Value
+		}
+		means[i] = s / float64(len(v))
+	}
+	return means
+}
@@ -0,0 +1,206 @@
+// Copyright (c) 2014 Feng Wang <wffrank1987@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language
+
+package knn
+
+import (
+	gomath "math"
+
+	"github.com/numb3r3/gorec/core"
+	"github.com/numb3r3/gorec/math"
+)
+
+// How the values of the neighbors are aggregated into a prediction
+type Aggregation int
+
+const (
+	// The similarity-weighted average of the neighbors' values
+	// (the raw weighted sum is used for ranking), suited for implicit data
+	WeightedSum Aggregation = iota
+	// The user mean plus the weighted average of the neighbors'
+	// deviations from their own means
+	MeanCentered
+	// Like MeanCentered, but the deviations are normalized by the
+	// standard deviations of the users
+	ZScore
+)
+
+// The options of the user-based collaborative filtering
+type UserKNNOptions struct {
+
+	// The similarity measure between the users
+	Similarity Similarity
+
+	// The number of neighbors kept per user, unlimited if not positive
+	K int
+
+	// Only the users with similarity above the threshold are neighbors
+	Threshold float64
+
+	// Shrink the similarity of the users sharing few items
+	Shrinkage float64
+
+	// How the values of the neighbors are aggregated
+	Aggregation Aggregation
+
+	// Whether to fit on the explicit ratings instead of the implicit events
+	UseRatings bool
+
+	// The weights of the implicit events, nil for the default weights
+	Weights core.EventWeights
+}
+
+func DefaultUserKNNOptions() UserKNNOptions {
+	return UserKNNOptions{Similarity: Cosine, K: 50, Aggregation: WeightedSum}
+}
+
+// The user-based k-nearest-neighbors recommender. The items of the most
+// similar users which the user has not interacted with are recommended.
+type UserKNN struct {
+	options UserKNNOptions
+
+	// The items of each user
+	userItems [][]math.SparseEntry
+
+	// The mean and standard deviation of each user
+	userMeans, userStds []float64
+
+	// The neighbors of each user
+	neighbors [][]Neighbor
+}
+
+func NewUserKNN(options UserKNNOptions) *UserKNN {
+	model := new(UserKNN)
+	model.options = options
+	return model
+}
+
+func (m *UserKNN) Fit(records []*core.Record) error {
+	if len(records) == 0 {
+		return core.ErrorEmptyRecords
+	}
+	var R *math.SparseMatrix
+	var err error
+	if m.options.UseRatings {
+		R, err = core.RatingMatrix(records, 0, 0)
+	} else {
+		R, err = core.InteractionMatrix(records, m.options.Weights, 0, 0)
+	}
+	if err != nil {
+		return err
+	}
+	return m.Train(R)
+}
+
+// Train the model on the user x item matrix
+func (m *UserKNN) Train(R *math.SparseMatrix) error {
+	if R.Rows() == 0 || R.Cols() == 0 {
+		return core.ErrorEmptyRecords
+	}
+	m.userItems = R.RowEntries()
+	m.userMeans = entryMeans(m.userItems)
+	m.userStds = make([]float64, len(m.userItems))
+	for u, items := range m.userItems {
+		var s float64
+		for _, e := range items {
+			s += (e.Value - m.userMeans[u]) * (e.Value - m.userMeans[u])
+		}
+		if len(items) > 0 {
+			m.userStds[u] = gomath.Sqrt(s / float64(len(items)))
+		}
+	}
+	itemUsers := R.ColEntries()
+	m.neighbors = computeNeighbors(m.userItems, itemUsers, entryMeans(itemUsers),
+		m.options.Similarity, m.options.Shrinkage, m.options.K, m.options.Threshold, nil)
+	return nil
+}
+
+// Get the neighbors of the user ordered by descending similarity
+func (m *UserKNN) Neighbors(userId int) []Neighbor {
+	if userId < 0 || userId >= len(m.neighbors) {
+		return nil
+	}
+	return m.neighbors[userId]
+}
+
+// The weighted value of the neighbor v, normalized by the aggregation
+func (m *UserKNN) deviation(v int, value float64) float64 {
+	switch m.options.Aggregation {
+	case MeanCentered:
+		return value - m.userMeans[v]
+	case ZScore:
+		if m.userStds[v] == 0 {
+			return 0
+		}
+		return (value - m.userMeans[v]) / m.userStds[v]
+	}
+	return value
+}
+
+// Turn the aggregated deviation of the neighbors back into a value of u
+func (m *UserKNN) restore(u int, deviation float64) float64 {
+	switch m.options.Aggregation {
+	case MeanCentered:
+		return m.userMeans[u] + deviation
+	case ZScore:
+		return m.userMeans[u] + m.userStds[u]*deviation
+	}
+	return deviation
+}
+
+// Score the items the user has not interacted with
+func (m *UserKNN) Scores(userId int) map[int]float64 {
+	if userId < 0 || userId >= len(m.userItems) {
+		return nil
+	}
+	sums := make(map[int]float64)
+	weights := make(map[int]float64)
+	for _, n := range m.neighbors[userId] {
+		for _, e := range m.userItems[n.Index] {
+			sums[e.Index] += n.Similarity * m.deviation(n.Index, e.Value)
+			weights[e.Index] += gomath.Abs(n.Similarity)
+		}
+	}
+	for _, e := range m.userItems[userId] {
+		delete(sums, e.Index)
+	}
+	for i, sum := range sums {
+		if m.options.Aggregation != WeightedSum {
+			sums[i] = m.restore(userId, sum/weights[i])
+		}
+	}
+	return sums
+}
+
+func (m *UserKNN) Recommend(userId int, context *core.Context, n int) []*core.ScoredProduct {
+	return core.TopN(m.Scores(userId), n)
+}
+
+// Predict the value of the user on the item from the neighbors who have
+// interacted with the item. The user mean is returned if there is none.
+func (m *UserKNN) Predict(userId, item int) float64 {
+	if userId < 0 || userId >= len(m.userItems) {
+		return 0
+	}
+	var sum, weights float64
+	for _, n := range m.neighbors[userId] {
+		if v, ok := math.LookupEntry(m.userItems[n.Index], item); ok {
+			sum += n.Similarity * m.deviation(n.Index, v)
+			weights += gomath.Abs(n.Similarity)
+		}
+	}
+	if weights == 0 {
+		return m.userMeans[userId]
+	}
+	return m.restore(userId, sum/weights)
+}
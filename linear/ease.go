@@ -0,0 +1,154 @@
+// Copyright (c) 2014 Feng Wang <wffrank1987@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language
+
+package linear
+
+import (
+	"github.com/numb3r3/gorec/core"
+	"github.com/numb3r3/gorec/math"
+)
+
+// The options of EASE
+type EASEOptions struct {
+
+	// The L2 regularization added to the diagonal of the Gram matrix,
+	// it must be positive unless the Gram matrix is positive definite
+	Lambda float64
+
+	// Whether to regard every interaction as 1 instead of its value
+	Binarize bool
+
+	// The weights of the implicit events, nil for the default weights
+	Weights core.EventWeights
+}
+
+func DefaultEASEOptions() EASEOptions {
+	return EASEOptions{Lambda: 500, Binarize: true}
+}
+
+// The embarrassingly shallow autoencoder (Steck, 2019). The item-item
+// weight matrix B minimizing ||X - XB||^2 + lambda ||B||^2 with a zero
+// diagonal has the closed form
+//
+//	P = (X^T X + lambda I)^-1,  B_ij = -P_ij / P_jj
+//
+// It needs the dense Cholesky inversion of an items x items matrix.
+type EASE struct {
+	options EASEOptions
+
+	// items x items
+	B *math.DenseMatrix
+
+	userItems [][]math.SparseEntry
+}
+
+func NewEASE(options EASEOptions) *EASE {
+	m := new(EASE)
+	m.options = options
+	return m
+}
+
+func (m *EASE) Fit(records []*core.Record) error {
+	if len(records) == 0 {
+		return core.ErrorEmptyRecords
+	}
+	R, err := core.InteractionMatrix(records, m.options.Weights, 0, 0)
+	if err != nil {
+		return err
+	}
+	return m.Train(R)
+}
+
+// Train on the user x item interaction matrix
+func (m *EASE) Train(R *math.SparseMatrix) error {
+	if R.Rows() == 0 || R.Cols() == 0 {
+		return core.ErrorEmptyRecords
+	}
+	X := prepare(R, m.options.Binarize)
+	m.userItems = X.RowEntries()
+
+	G := X.Gram()
+	for i := 0; i < G.Rows(); i++ {
+		G.Set(i, i, G.Get(i, i)+m.options.Lambda)
+	}
+	// G is symmetric positive definite for lambda > 0, and it is
+	// turned into P and then into B in place
+	if err := G.InverseSPD(); err != nil {
+		return err
+	}
+	n := G.Rows()
+	diagonal := make([]float64, n)
+	for j := 0; j < n; j++ {
+		diagonal[j] = G.Get(j, j)
+	}
+	for i := 0; i < n; i++ {
+		row := G.RowSlice(i)
+		for j := range row {
+			if i == j {
+				row[j] = 0
+			} else {
+				row[j] = -row[j] / diagonal[j]
+			}
+		}
+	}
+	m.B = G
+	return nil
+}
+
+// Get the items x items weight matrix
+func (m *EASE) Weights() *math.DenseMatrix {
+	return m.B
+}
+
+// Get the reconstructed value of the user on the item
+func (m *EASE) Predict(userId, item int) float64 {
+	if m.B == nil || userId < 0 || userId >= len(m.userItems) || item < 0 || item >= m.B.Cols() {
+		return 0
+	}
+	var s float64
+	for _, e := range m.userItems[userId] {
+		s += e.Value * m.B.Get(e.Index, item)
+	}
+	return s
+}
+
+func (m *EASE) Recommend(userId int, context *core.Context, n int) []*core.ScoredProduct {
+	if m.B == nil || userId < 0 || userId >= len(m.userItems) {
+		return nil
+	}
+	scores := make([]float64, m.B.Cols())
+	for _, e := range m.userItems[userId] {
+		brow := m.B.RowSlice(e.Index)
+		for j, w := range brow {
+			scores[j] += e.Value * w
+		}
+	}
+	return core.TopNSlice(scores, n, seen(m.userItems[userId]))
+}
+
+// Copy the matrix, replacing the non-zero values by 1 if binarize is true
+func prepare(R *math.SparseMatrix, binarize bool) *math.SparseMatrix {
+	X := R.Copy()
+	if binarize {
+		R.Each(func(i, j int, v float64) { X.Set(i, j, 1) })
+	}
+	return X
+}
+
+func seen(items []math.SparseEntry) map[int]bool {
+	s := make(map[int]bool, len(items))
+	for _, e := range items {
+		s[e.Index] = true
+	}
+	return s
+}
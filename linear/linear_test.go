@@ -0,0 +1,105 @@
+// Copyright (c) 2014 Feng Wang <wffrank1987@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language
+
+package linear
+
+import (
+	"github.com/numb3r3/gorec/core"
+	"github.com/numb3r3/gorec/math"
+	"github.com/numb3r3/gorec/utils"
+	"testing"
+	"time"
+)
+
+func interactions(users [][]int) []*core.Record {
+	var records []*core.Record
+	for u, items := range users {
+		for _, i := range items {
+			records = append(records, core.NewEvent(u, i, core.EventView, time.Time{}))
+		}
+	}
+	return records
+}
+
+func TestEASE(t *testing.T) {
+	users := [][]int{{0, 1}, {0, 1, 2}, {1, 2}, {2, 3}, {0}}
+	options := DefaultEASEOptions()
+	options.Lambda = 1
+	model := NewEASE(options)
+	if err := model.Fit(interactions(users)); err != nil {
+		t.Fatal(err)
+	}
+
+	// compare with the closed form by the Gauss-Jordan inverse
+	R, _ := core.InteractionMatrix(interactions(users), nil, 0, 0)
+	X := prepare(R, true)
+	G := X.Gram()
+	for i := 0; i < G.Rows(); i++ {
+		G.Set(i, i, G.Get(i, i)+1)
+	}
+	P, err := G.Inverse()
+	if err != nil {
+		t.Fatal(err)
+	}
+	B := model.Weights()
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 4; j++ {
+			expect := 0.0
+			if i != j {
+				expect = -P.Get(i, j) / P.Get(j, j)
+			}
+			utils.ExpectNear(t, expect, B.Get(i, j), 1e-9)
+		}
+	}
+
+	// user 4 with item 0 is recommended item 1 first
+	result := model.Recommend(4, nil, 3)
+	utils.Expect(t, "3", len(result))
+	utils.Expect(t, "1", result[0].ProductId)
+	utils.ExpectNear(t, B.Get(0, 1), model.Predict(4, 1), 1e-12)
+
+	// the Gram matrix is singular without regularization for the item 1
+	// nobody interacted with
+	options.Lambda = 0
+	err = NewEASE(options).Fit(interactions([][]int{{0, 2}, {2}}))
+	utils.Expect(t, math.ExceptionNotSPD.Error(), err)
+}
+
+func TestSLIM(t *testing.T) {
+	// the items 0 and 1 co-occur twice, item 0 is also alone once
+	users := [][]int{{0, 1}, {0, 1}, {0}}
+	options := DefaultSLIMOptions()
+	options.L1 = 1
+	options.L2 = 1
+	model := NewSLIM(options)
+	if err := model.Fit(interactions(users)); err != nil {
+		t.Fatal(err)
+	}
+	// W_01 = (x_0 . x_1 - l1) / (|x_0|^2 + l2) = (2 - 1) / (3 + 1)
+	utils.ExpectNear(t, 0.25, model.Weight(0, 1), 1e-9)
+	// W_10 = (2 - 1) / (2 + 1)
+	utils.ExpectNear(t, 1.0/3, model.Weight(1, 0), 1e-9)
+	utils.ExpectNear(t, 0, model.Weight(0, 0), 1e-12)
+
+	result := model.Recommend(2, nil, 2)
+	utils.Expect(t, "1", len(result))
+	utils.Expect(t, "1", result[0].ProductId)
+	utils.ExpectNear(t, 0.25, result[0].Score, 1e-9)
+
+	// all of the weights are cut by the large L1
+	options.L1 = 3
+	model = NewSLIM(options)
+	model.Fit(interactions(users))
+	utils.ExpectNear(t, 0, model.Weight(0, 1), 1e-12)
+	utils.Expect(t, "0", len(model.Recommend(2, nil, 2)))
+}
@@ -0,0 +1,267 @@
+// Copyright (c) 2014 Feng Wang <wffrank1987@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language
+
+package linear
+
+import (
+	gomath "math"
+	"runtime"
+	"sort"
+	"sync"
+
+	"github.com/numb3r3/gorec/core"
+	"github.com/numb3r3/gorec/math"
+)
+
+// The options of SLIM
+type SLIMOptions struct {
+
+	// The L1 and L2 regularization of the weights
+	L1, L2 float64
+
+	// Whether to constrain the weights to be non-negative
+	Positive bool
+
+	// The maximum number of the coordinate descent sweeps per item
+	NumIterations int
+
+	// Stop when the largest change of a weight in a sweep is below Tolerance
+	Tolerance float64
+
+	// The weights of item j are learned only on the K items co-occurring
+	// with j most often, all co-occurring items if not positive
+	K int
+
+	// Whether to regard every interaction as 1 instead of its value
+	Binarize bool
+
+	// The weights of the implicit events, nil for the default weights
+	Weights core.EventWeights
+}
+
+func DefaultSLIMOptions() SLIMOptions {
+	return SLIMOptions{
+		L1:            1,
+		L2:            10,
+		Positive:      true,
+		NumIterations: 50,
+		Tolerance:     1e-4,
+		K:             200,
+		Binarize:      true,
+	}
+}
+
+// The sparse linear method (Ning and Karypis, 2011). Each column w_j of
+// the item-item weight matrix solves the elastic net
+//
+//	min 1/2 ||x_j - X w_j||^2 + l1 ||w_j||_1 + l2/2 ||w_j||^2,  w_jj = 0
+//
+// by coordinate descent, restricted to the items co-occurring with j.
+type SLIM struct {
+	options SLIMOptions
+
+	// item k -> the items j with non-zero W_kj
+	weights [][]math.SparseEntry
+
+	userItems [][]math.SparseEntry
+}
+
+func NewSLIM(options SLIMOptions) *SLIM {
+	m := new(SLIM)
+	m.options = options
+	return m
+}
+
+func (m *SLIM) Fit(records []*core.Record) error {
+	if len(records) == 0 {
+		return core.ErrorEmptyRecords
+	}
+	R, err := core.InteractionMatrix(records, m.options.Weights, 0, 0)
+	if err != nil {
+		return err
+	}
+	return m.Train(R)
+}
+
+// Train on the user x item interaction matrix
+func (m *SLIM) Train(R *math.SparseMatrix) error {
+	if R.Rows() == 0 || R.Cols() == 0 {
+		return core.ErrorEmptyRecords
+	}
+	X := prepare(R, m.options.Binarize)
+	m.userItems = X.RowEntries()
+	itemUsers := X.ColEntries()
+	norms := make([]float64, len(itemUsers))
+	for k, users := range itemUsers {
+		for _, e := range users {
+			norms[k] += e.Value * e.Value
+		}
+	}
+
+	numItems := len(itemUsers)
+	columns := make([][]math.SparseEntry, numItems)
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < runtime.NumCPU(); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			residual := make([]float64, X.Rows())
+			for j := range jobs {
+				columns[j] = m.solveColumn(j, itemUsers, norms, residual)
+			}
+		}()
+	}
+	for j := 0; j < numItems; j++ {
+		jobs <- j
+	}
+	close(jobs)
+	wg.Wait()
+
+	// transpose the columns into rows for scoring
+	m.weights = make([][]math.SparseEntry, numItems)
+	for j, column := range columns {
+		for _, e := range column {
+			m.weights[e.Index] = append(m.weights[e.Index], math.SparseEntry{Index: j, Value: e.Value})
+		}
+	}
+	return nil
+}
+
+// Learn the weights of column j, residual is a zeroed buffer of the users
+func (m *SLIM) solveColumn(j int, itemUsers [][]math.SparseEntry, norms, residual []float64) []math.SparseEntry {
+	o := m.options
+	candidates := m.candidates(j, itemUsers)
+
+	for _, e := range itemUsers[j] {
+		residual[e.Index] = e.Value
+	}
+	w := make([]float64, len(candidates))
+	for iter := 0; iter < o.NumIterations; iter++ {
+		var maxDelta float64
+		for c, k := range candidates {
+			denominator := norms[k] + o.L2
+			if denominator == 0 {
+				continue
+			}
+			rho := norms[k] * w[c]
+			for _, e := range itemUsers[k] {
+				rho += e.Value * residual[e.Index]
+			}
+			updated := softThreshold(rho, o.L1) / denominator
+			if o.Positive && updated < 0 {
+				updated = 0
+			}
+			delta := updated - w[c]
+			if delta == 0 {
+				continue
+			}
+			for _, e := range itemUsers[k] {
+				residual[e.Index] -= e.Value * delta
+			}
+			w[c] = updated
+			maxDelta = gomath.Max(maxDelta, gomath.Abs(delta))
+		}
+		if maxDelta < o.Tolerance {
+			break
+		}
+	}
+
+	// reset the touched users of the buffer
+	for _, e := range itemUsers[j] {
+		residual[e.Index] = 0
+	}
+	for _, k := range candidates {
+		for _, e := range itemUsers[k] {
+			residual[e.Index] = 0
+		}
+	}
+
+	var column []math.SparseEntry
+	for c, k := range candidates {
+		if w[c] != 0 {
+			column = append(column, math.SparseEntry{Index: k, Value: w[c]})
+		}
+	}
+	return column
+}
+
+// Get the items co-occurring with item j, the most frequent K of them
+func (m *SLIM) candidates(j int, itemUsers [][]math.SparseEntry) []int {
+	counts := make(map[int]int)
+	for _, u := range itemUsers[j] {
+		for _, e := range m.userItems[u.Index] {
+			if e.Index != j {
+				counts[e.Index]++
+			}
+		}
+	}
+	items := make([]int, 0, len(counts))
+	for k := range counts {
+		items = append(items, k)
+	}
+	sort.Slice(items, func(a, b int) bool {
+		if counts[items[a]] != counts[items[b]] {
+			return counts[items[a]] > counts[items[b]]
+		}
+		return items[a] < items[b]
+	})
+	if m.options.K > 0 && len(items) > m.options.K {
+		items = items[:m.options.K]
+	}
+	return items
+}
+
+func softThreshold(x, threshold float64) float64 {
+	switch {
+	case x > threshold:
+		return x - threshold
+	case x < -threshold:
+		return x + threshold
+	}
+	return 0
+}
+
+// Get the weight W_kj of item k in the reconstruction of item j
+func (m *SLIM) Weight(k, j int) float64 {
+	if k < 0 || k >= len(m.weights) {
+		return 0
+	}
+	for _, e := range m.weights[k] {
+		if e.Index == j {
+			return e.Value
+		}
+	}
+	return 0
+}
+
+// Score the items the user has not interacted with
+func (m *SLIM) Scores(userId int) map[int]float64 {
+	if userId < 0 || userId >= len(m.userItems) {
+		return nil
+	}
+	scores := make(map[int]float64)
+	for _, e := range m.userItems[userId] {
+		for _, w := range m.weights[e.Index] {
+			scores[w.Index] += e.Value * w.Value
+		}
+	}
+	for _, e := range m.userItems[userId] {
+		delete(scores, e.Index)
+	}
+	return scores
+}
+
+func (m *SLIM) Recommend(userId int, context *core.Context, n int) []*core.ScoredProduct {
+	return core.TopN(m.Scores(userId), n)
+}
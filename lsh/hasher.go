@@ -0,0 +1,146 @@
+// Copyright (c) 2014 Feng Wang <wffrank1987@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language
+
+package lsh
+
+import (
+	gomath "math"
+	"math/rand"
+
+	"github.com/numb3r3/gorec/math"
+)
+
+// A locality-sensitive hash family. The signatures of similar vectors
+// agree on many positions.
+type Hasher interface {
+	// Get the signature of the sparse vector
+	Signature(entries []math.SparseEntry) []uint64
+
+	// Estimate the similarity of two vectors from their signatures
+	Similarity(a, b []uint64) float64
+}
+
+// MinHash for the Jaccard similarity of the sets of the non-zero indexes.
+// Position i of the signature is the minimum of the i-th hash function over
+// the set, two sets agree on it with the probability of their Jaccard.
+type MinHash struct {
+	seeds []uint64
+}
+
+// Create the MinHash of signatures of the length
+func NewMinHash(length int, seed int64) *MinHash {
+	rng := rand.New(rand.NewSource(seed))
+	h := &MinHash{seeds: make([]uint64, length)}
+	for i := range h.seeds {
+		h.seeds[i] = rng.Uint64()
+	}
+	return h
+}
+
+func (h *MinHash) Signature(entries []math.SparseEntry) []uint64 {
+	signature := make([]uint64, len(h.seeds))
+	for i := range signature {
+		signature[i] = gomath.MaxUint64
+	}
+	for _, e := range entries {
+		if e.Value == 0 {
+			continue
+		}
+		for i, seed := range h.seeds {
+			if v := mix(uint64(e.Index) ^ seed); v < signature[i] {
+				signature[i] = v
+			}
+		}
+	}
+	return signature
+}
+
+func (h *MinHash) Similarity(a, b []uint64) float64 {
+	return agreement(a, b)
+}
+
+// SimHash, the random hyperplane hash for the cosine similarity. Position
+// i of the signature is the side of the i-th random hyperplane the vector
+// lies on, two vectors at angle theta agree on it with the probability
+// 1 - theta / pi. The hyperplanes have random +1/-1 components derived
+// from the indexes, so they need no dimension.
+type SimHash struct {
+	seeds []uint64
+}
+
+// Create the SimHash of signatures of the length
+func NewSimHash(length int, seed int64) *SimHash {
+	rng := rand.New(rand.NewSource(seed))
+	h := &SimHash{seeds: make([]uint64, length)}
+	for i := range h.seeds {
+		h.seeds[i] = rng.Uint64()
+	}
+	return h
+}
+
+func (h *SimHash) Signature(entries []math.SparseEntry) []uint64 {
+	signature := make([]uint64, len(h.seeds))
+	for i, seed := range h.seeds {
+		var projection float64
+		for _, e := range entries {
+			if mix(uint64(e.Index)^seed)&1 == 1 {
+				projection += e.Value
+			} else {
+				projection -= e.Value
+			}
+		}
+		if projection >= 0 {
+			signature[i] = 1
+		}
+	}
+	return signature
+}
+
+func (h *SimHash) Similarity(a, b []uint64) float64 {
+	return gomath.Cos(gomath.Pi * (1 - agreement(a, b)))
+}
+
+// Get the fraction of the positions the signatures agree on
+func agreement(a, b []uint64) float64 {
+	if len(a) == 0 {
+		return 0
+	}
+	same := 0
+	for i, v := range a {
+		if v == b[i] {
+			same++
+		}
+	}
+	return float64(same) / float64(len(a))
+}
+
+// The splitmix64 finalizer
+func mix(x uint64) uint64 {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return x ^ (x >> 31)
+}
+
+// Get the non-zero entries of the vector, sorted by index
+func VectorEntries(v *math.Vector) []math.SparseEntry {
+	indexes := v.Indexes()
+	entries := make([]math.SparseEntry, 0, len(indexes))
+	for _, i := range indexes {
+		if value := v.Get(i); value != 0 {
+			entries = append(entries, math.SparseEntry{Index: i, Value: value})
+		}
+	}
+	sortEntries(entries)
+	return entries
+}
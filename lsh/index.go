@@ -0,0 +1,300 @@
+// Copyright (c) 2014 Feng Wang <wffrank1987@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language
+
+package lsh
+
+import (
+	gomath "math"
+	"sort"
+	"sync"
+
+	"github.com/numb3r3/gorec/math"
+)
+
+// The options of the banding index
+type IndexOptions struct {
+
+	// The signature is split into Bands bands of Rows positions each
+	Bands, Rows int
+
+	// The seed of the hash functions
+	Seed int64
+}
+
+func DefaultIndexOptions() IndexOptions {
+	return IndexOptions{Bands: 20, Rows: 5, Seed: 1}
+}
+
+// Get the similarity at which a pair becomes a candidate with probability
+// about 1/2, approximately (1/Bands)^(1/Rows)
+func (o IndexOptions) Threshold() float64 {
+	return gomath.Pow(1/float64(o.Bands), 1/float64(o.Rows))
+}
+
+// A candidate neighbor with its estimated similarity
+type Neighbor struct {
+	Id int
+
+	Similarity float64
+}
+
+// The banding index. The vectors whose signatures agree on all of the
+// positions of any band fall into the same bucket of that band and are
+// candidates of each other, so similar pairs are found without comparing
+// all of the pairs. The index is safe for concurrent use.
+type Index struct {
+	options IndexOptions
+
+	hasher Hasher
+
+	// id -> signature
+	signatures map[int][]uint64
+
+	// band -> band key -> ids
+	buckets []map[uint64][]int
+
+	mutex sync.RWMutex
+}
+
+// Create the index of the hasher with signatures of Bands * Rows positions
+func NewIndex(hasher Hasher, options IndexOptions) *Index {
+	index := new(Index)
+	index.options = options
+	index.hasher = hasher
+	index.signatures = make(map[int][]uint64)
+	index.buckets = make([]map[uint64][]int, options.Bands)
+	for b := range index.buckets {
+		index.buckets[b] = make(map[uint64][]int)
+	}
+	return index
+}
+
+// Create the index for the Jaccard similarity
+func NewMinHashIndex(options IndexOptions) *Index {
+	return NewIndex(NewMinHash(options.Bands*options.Rows, options.Seed), options)
+}
+
+// Create the index for the cosine similarity
+func NewSimHashIndex(options IndexOptions) *Index {
+	return NewIndex(NewSimHash(options.Bands*options.Rows, options.Seed), options)
+}
+
+func (index *Index) bandKey(signature []uint64, band int) uint64 {
+	key := uint64(band)
+	for _, v := range signature[band*index.options.Rows : (band+1)*index.options.Rows] {
+		key = mix(key ^ v)
+	}
+	return key
+}
+
+// Add the sparse vector with the id, replacing the previous vector of the id.
+// The vectors without non-zero entries are not indexed.
+func (index *Index) Add(id int, entries []math.SparseEntry) {
+	signature := index.hasher.Signature(entries)
+	index.mutex.Lock()
+	defer index.mutex.Unlock()
+	index.remove(id)
+	if !hasNonZero(entries) {
+		return
+	}
+	index.signatures[id] = signature
+	for b, buckets := range index.buckets {
+		key := index.bandKey(signature, b)
+		buckets[key] = append(buckets[key], id)
+	}
+}
+
+// Add the vector with the id
+func (index *Index) AddVector(id int, v *math.Vector) {
+	index.Add(id, VectorEntries(v))
+}
+
+// Add the rows of the matrix, the id of a row is its row number
+func (index *Index) AddRows(M *math.SparseMatrix) {
+	for i, entries := range M.RowEntries() {
+		index.Add(i, entries)
+	}
+}
+
+// Add the columns of the matrix, the id of a column is its column number
+func (index *Index) AddCols(M *math.SparseMatrix) {
+	for j, entries := range M.ColEntries() {
+		index.Add(j, entries)
+	}
+}
+
+// Remove the vector of the id
+func (index *Index) Remove(id int) {
+	index.mutex.Lock()
+	defer index.mutex.Unlock()
+	index.remove(id)
+}
+
+func (index *Index) remove(id int) {
+	signature, ok := index.signatures[id]
+	if !ok {
+		return
+	}
+	delete(index.signatures, id)
+	for b, buckets := range index.buckets {
+		key := index.bandKey(signature, b)
+		ids := buckets[key]
+		for i, other := range ids {
+			if other == id {
+				ids = append(ids[:i], ids[i+1:]...)
+				break
+			}
+		}
+		if len(ids) == 0 {
+			delete(buckets, key)
+		} else {
+			buckets[key] = ids
+		}
+	}
+}
+
+// Get the number of the indexed vectors
+func (index *Index) Len() int {
+	index.mutex.RLock()
+	defer index.mutex.RUnlock()
+	return len(index.signatures)
+}
+
+// Get the sorted ids sharing a bucket with the signature
+func (index *Index) candidates(signature []uint64) []int {
+	found := make(map[int]bool)
+	for b, buckets := range index.buckets {
+		for _, id := range buckets[index.bandKey(signature, b)] {
+			found[id] = true
+		}
+	}
+	ids := make([]int, 0, len(found))
+	for id := range found {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// Get the candidate neighbors of the sparse vector
+func (index *Index) Query(entries []math.SparseEntry) []int {
+	if !hasNonZero(entries) {
+		return nil
+	}
+	signature := index.hasher.Signature(entries)
+	index.mutex.RLock()
+	defer index.mutex.RUnlock()
+	return index.candidates(signature)
+}
+
+// Get the candidate neighbors of the indexed id, excluding itself. It can
+// be the candidates of the item-kNN to avoid the all-pairs comparison.
+func (index *Index) CandidatesOf(id int) []int {
+	index.mutex.RLock()
+	defer index.mutex.RUnlock()
+	signature, ok := index.signatures[id]
+	if !ok {
+		return nil
+	}
+	return index.others(id, signature)
+}
+
+// Get the sorted ids sharing a bucket with the indexed id, except itself
+func (index *Index) others(id int, signature []uint64) []int {
+	ids := index.candidates(signature)
+	// the id always shares the buckets of its own signature
+	i := sort.SearchInts(ids, id)
+	return append(ids[:i], ids[i+1:]...)
+}
+
+// Estimate the similarity of two indexed ids from their signatures
+func (index *Index) Similarity(a, b int) float64 {
+	index.mutex.RLock()
+	defer index.mutex.RUnlock()
+	sa, okA := index.signatures[a]
+	sb, okB := index.signatures[b]
+	if !okA || !okB {
+		return 0
+	}
+	return index.hasher.Similarity(sa, sb)
+}
+
+// Get the k candidates of the id with the highest estimated similarity,
+// all of them if k is not positive
+func (index *Index) Neighbors(id, k int) []Neighbor {
+	index.mutex.RLock()
+	defer index.mutex.RUnlock()
+	signature, ok := index.signatures[id]
+	if !ok {
+		return nil
+	}
+	var neighbors []Neighbor
+	for _, c := range index.others(id, signature) {
+		neighbors = append(neighbors, Neighbor{c, index.hasher.Similarity(signature, index.signatures[c])})
+	}
+	sort.SliceStable(neighbors, func(a, b int) bool {
+		return neighbors[a].Similarity > neighbors[b].Similarity
+	})
+	if k > 0 && len(neighbors) > k {
+		neighbors = neighbors[:k]
+	}
+	return neighbors
+}
+
+// Get the candidate pairs (a < b) with estimated similarity of at least
+// threshold, sorted by ids
+func (index *Index) NearDuplicates(threshold float64) [][2]int {
+	index.mutex.RLock()
+	defer index.mutex.RUnlock()
+	seen := make(map[[2]int]bool)
+	var pairs [][2]int
+	for _, buckets := range index.buckets {
+		for _, ids := range buckets {
+			for i, a := range ids {
+				for _, b := range ids[i+1:] {
+					pair := [2]int{a, b}
+					if a > b {
+						pair = [2]int{b, a}
+					}
+					if seen[pair] {
+						continue
+					}
+					seen[pair] = true
+					if index.hasher.Similarity(index.signatures[a], index.signatures[b]) >= threshold {
+						pairs = append(pairs, pair)
+					}
+				}
+			}
+		}
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i][0] != pairs[j][0] {
+			return pairs[i][0] < pairs[j][0]
+		}
+		return pairs[i][1] < pairs[j][1]
+	})
+	return pairs
+}
+
+func hasNonZero(entries []math.SparseEntry) bool {
+	for _, e := range entries {
+		if e.Value != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func sortEntries(entries []math.SparseEntry) {
+	sort.Slice(entries, func(a, b int) bool { return entries[a].Index < entries[b].Index })
+}
@@ -0,0 +1,77 @@
+// Copyright (c) 2014 Feng Wang <wffrank1987@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language
+
+package lsh
+
+import (
+	"fmt"
+	"github.com/numb3r3/gorec/math"
+	"github.com/numb3r3/gorec/utils"
+	"testing"
+)
+
+func set(indexes ...int) []math.SparseEntry {
+	entries := make([]math.SparseEntry, len(indexes))
+	for i, index := range indexes {
+		entries[i] = math.SparseEntry{Index: index, Value: 1}
+	}
+	return entries
+}
+
+func span(from, to int) []math.SparseEntry {
+	var indexes []int
+	for i := from; i < to; i++ {
+		indexes = append(indexes, i)
+	}
+	return set(indexes...)
+}
+
+func TestMinHash(t *testing.T) {
+	h := NewMinHash(1000, 1)
+	// Jaccard of [0, 60) and [20, 80) is 40 / 80
+	similarity := h.Similarity(h.Signature(span(0, 60)), h.Signature(span(20, 80)))
+	utils.ExpectNear(t, 0.5, similarity, 0.05)
+}
+
+func TestSimHash(t *testing.T) {
+	h := NewSimHash(2000, 1)
+	// cosine of [0, 60) and [20, 80) is 40 / 60
+	similarity := h.Similarity(h.Signature(span(0, 60)), h.Signature(span(20, 80)))
+	utils.ExpectNear(t, 2.0/3, similarity, 0.08)
+
+	v := math.NewSparseVector()
+	for _, e := range span(0, 60) {
+		v.Set(e.Index, e.Value)
+	}
+	utils.Expect(t, fmt.Sprint(h.Signature(span(0, 60))), fmt.Sprint(h.Signature(VectorEntries(v))))
+}
+
+func TestNearDuplicates(t *testing.T) {
+	index := NewMinHashIndex(DefaultIndexOptions())
+	index.Add(0, span(0, 100))
+	index.Add(1, span(1, 101))
+	index.Add(2, span(500, 600))
+	index.Add(3, span(2, 100))
+	index.Add(4, nil)
+	utils.Expect(t, "[[0 1] [0 3] [1 3]]", fmt.Sprint(index.NearDuplicates(0.8)))
+	utils.Expect(t, "[]", fmt.Sprint(index.CandidatesOf(2)))
+
+	index.Remove(1)
+	utils.Expect(t, "[3]", fmt.Sprint(index.CandidatesOf(0)))
+	utils.Expect(t, "3", fmt.Sprint(index.Len()))
+
+	// a negative id is a regular id of the queries
+	index.Add(-1, span(0, 99))
+	utils.Expect(t, "[-1 0 3]", fmt.Sprint(index.Query(span(0, 100))))
+	utils.Expect(t, "[0 3]", fmt.Sprint(index.CandidatesOf(-1)))
+}
@@ -243,6 +243,16 @@ func Normals(rows, cols int) *DenseMatrix {
 	return N
 }
 
+// Create a matrix with the elements drawn from N(0, std^2) by rng,
+// used to initialize the latent factors
+func RandomNormals(rows, cols int, std float64, rng *rand.Rand) *DenseMatrix {
+	N := Zeros(rows, cols)
+	for i := range N.elements {
+		N.elements[i] = rng.NormFloat64() * std
+	}
+	return N
+}
+
 func Diagonal(d []float64) *DenseMatrix {
 	n := len(d)
 	D := Zeros(n, n)
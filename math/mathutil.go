@@ -63,3 +63,12 @@ func product(a []float64) float64 {
 	}
 	return p
 }
+
+// Get the dot product of the two slices of the same length
+func DotSlices(a, b []float64) float64 {
+	var s float64
+	for i, v := range a {
+		s += v * b[i]
+	}
+	return s
+}
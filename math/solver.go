@@ -0,0 +1,235 @@
+// Copyright (c) 2014 Feng Wang <wffrank1987@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language
+
+package math
+
+import (
+	"math"
+)
+
+// Get the transpose of the matrix
+func (M *DenseMatrix) Transpose() *DenseMatrix {
+	T := Zeros(M.cols, M.rows)
+	for i := 0; i < M.rows; i++ {
+		for j := 0; j < M.cols; j++ {
+			T.elements[j*T.step+i] = M.elements[i*M.step+j]
+		}
+	}
+	return T
+}
+
+// Get the product M * A
+func (M *DenseMatrix) Times(A *DenseMatrix) (*DenseMatrix, error) {
+	if M.cols != A.rows {
+		return nil, ErrorDimensionMismatch
+	}
+	P := Zeros(M.rows, A.cols)
+	for i := 0; i < M.rows; i++ {
+		prow := P.RowSlice(i)
+		for k := 0; k < M.cols; k++ {
+			v := M.elements[i*M.step+k]
+			if v == 0 {
+				continue
+			}
+			arow := A.RowSlice(k)
+			for j := range prow {
+				prow[j] += v * arow[j]
+			}
+		}
+	}
+	return P, nil
+}
+
+// Get the Gram matrix M^T * M
+func (M *DenseMatrix) Gram() *DenseMatrix {
+	G := Zeros(M.cols, M.cols)
+	for r := 0; r < M.rows; r++ {
+		row := M.RowSlice(r)
+		for i, vi := range row {
+			if vi == 0 {
+				continue
+			}
+			grow := G.RowSlice(i)
+			for j := i; j < M.cols; j++ {
+				grow[j] += vi * row[j]
+			}
+		}
+	}
+	for i := 0; i < M.cols; i++ {
+		for j := 0; j < i; j++ {
+			G.elements[i*G.step+j] = G.elements[j*G.step+i]
+		}
+	}
+	return G
+}
+
+// Get the dense Gram matrix M^T * M of the sparse matrix
+func (M *SparseMatrix) Gram() *DenseMatrix {
+	G := Zeros(M.cols, M.cols)
+	for _, row := range M.RowEntries() {
+		for _, a := range row {
+			grow := G.RowSlice(a.Index)
+			for _, b := range row {
+				grow[b.Index] += a.Value * b.Value
+			}
+		}
+	}
+	return G
+}
+
+// Get the inverse of the square matrix by the Gauss-Jordan elimination
+// with partial pivoting
+func (M *DenseMatrix) Inverse() (*DenseMatrix, error) {
+	if M.rows != M.cols {
+		return nil, ErrorDimensionMismatch
+	}
+	n := M.rows
+	A := M.Copy()
+	I := Eye(n)
+	for c := 0; c < n; c++ {
+		// the row with the largest pivot
+		p := c
+		for r := c + 1; r < n; r++ {
+			if math.Abs(A.elements[r*A.step+c]) > math.Abs(A.elements[p*A.step+c]) {
+				p = r
+			}
+		}
+		if math.Abs(A.elements[p*A.step+c]) < 1e-12 {
+			return nil, ExceptionSingular
+		}
+		if p != c {
+			swapRows(A, p, c)
+			swapRows(I, p, c)
+		}
+
+		arow, irow := A.RowSlice(c), I.RowSlice(c)
+		pivot := arow[c]
+		for j := 0; j < n; j++ {
+			arow[j] /= pivot
+			irow[j] /= pivot
+		}
+		for r := 0; r < n; r++ {
+			if r == c {
+				continue
+			}
+			ar, ir := A.RowSlice(r), I.RowSlice(r)
+			f := ar[c]
+			if f == 0 {
+				continue
+			}
+			for j := 0; j < n; j++ {
+				ar[j] -= f * arow[j]
+				ir[j] -= f * irow[j]
+			}
+		}
+	}
+	return I, nil
+}
+
+func swapRows(M *DenseMatrix, a, b int) {
+	ra, rb := M.RowSlice(a), M.RowSlice(b)
+	for j := range ra {
+		ra[j], rb[j] = rb[j], ra[j]
+	}
+}
+
+// Compute the Cholesky decomposition M = L * L^T of a symmetric positive
+// definite matrix, L is lower triangular
+func (M *DenseMatrix) Cholesky() (*DenseMatrix, error) {
+	if M.rows != M.cols {
+		return nil, ErrorDimensionMismatch
+	}
+	n := M.rows
+	L := Zeros(n, n)
+	for j := 0; j < n; j++ {
+		lj := L.RowSlice(j)
+		d := M.Get(j, j)
+		for k := 0; k < j; k++ {
+			d -= lj[k] * lj[k]
+		}
+		if d <= 0 {
+			return nil, ExceptionNotSPD
+		}
+		lj[j] = math.Sqrt(d)
+		for i := j + 1; i < n; i++ {
+			li := L.RowSlice(i)
+			s := M.Get(i, j)
+			for k := 0; k < j; k++ {
+				s -= li[k] * lj[k]
+			}
+			li[j] = s / lj[j]
+		}
+	}
+	return L, nil
+}
+
+// Solve L * L^T * x = b, where L is the Cholesky factor
+func CholeskySolve(L *DenseMatrix, b []float64) ([]float64, error) {
+	n := L.rows
+	if len(b) != n {
+		return nil, ErrorDimensionMismatch
+	}
+	x := make([]float64, n)
+	choleskySolve(L, b, make([]float64, n), x)
+	return x, nil
+}
+
+// Solve L * L^T * x = b into x, with y as the buffer of L^T * x
+func choleskySolve(L *DenseMatrix, b, y, x []float64) {
+	n := L.rows
+	// forward substitution L * y = b
+	for i := 0; i < n; i++ {
+		li := L.RowSlice(i)
+		s := b[i]
+		for k := 0; k < i; k++ {
+			s -= li[k] * y[k]
+		}
+		y[i] = s / li[i]
+	}
+	// backward substitution L^T * x = y
+	for i := n - 1; i >= 0; i-- {
+		s := y[i]
+		for k := i + 1; k < n; k++ {
+			s -= L.elements[k*L.step+i] * x[k]
+		}
+		x[i] = s / L.elements[i*L.step+i]
+	}
+}
+
+// Solve M * x = b for a symmetric positive definite matrix M
+func SolveSPD(M *DenseMatrix, b []float64) ([]float64, error) {
+	L, err := M.Cholesky()
+	if err != nil {
+		return nil, err
+	}
+	return CholeskySolve(L, b)
+}
+
+// Invert a symmetric positive definite matrix in place by its Cholesky
+// decomposition, only the factor L and three vectors are allocated
+func (M *DenseMatrix) InverseSPD() error {
+	L, err := M.Cholesky()
+	if err != nil {
+		return err
+	}
+	n := M.rows
+	e, y, x := make([]float64, n), make([]float64, n), make([]float64, n)
+	for j := 0; j < n; j++ {
+		e[j] = 1
+		choleskySolve(L, e, y, x)
+		e[j] = 0
+		// the inverse is symmetric, so the column j is the row j
+		copy(M.RowSlice(j), x)
+	}
+	return nil
+}
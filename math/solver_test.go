@@ -0,0 +1,84 @@
+// Copyright (c) 2014 Feng Wang <wffrank1987@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language
+
+package math
+
+import (
+	"github.com/numb3r3/gorec/utils"
+	"testing"
+)
+
+func TestGram(t *testing.T) {
+	M := MakeDenseMatrixStacked([][]float64{[]float64{1, 2}, []float64{3, 4}, []float64{5, 6}})
+	G := M.Gram()
+	P, err := M.Transpose().Times(M)
+	if err != nil {
+		t.Fatal(err)
+	}
+	utils.Expect(t, "35", G.Get(0, 0))
+	utils.Expect(t, "44", G.Get(0, 1))
+	utils.Expect(t, "44", G.Get(1, 0))
+	utils.Expect(t, "56", G.Get(1, 1))
+	utils.Expect(t, G.String(), P)
+}
+
+func TestSolveSPD(t *testing.T) {
+	A := MakeDenseMatrixStacked([][]float64{[]float64{4, 12, -16}, []float64{12, 37, -43}, []float64{-16, -43, 98}})
+	L, err := A.Cholesky()
+	if err != nil {
+		t.Fatal(err)
+	}
+	utils.Expect(t, "[2 0 0 6 1 0 -8 5 3]", L.Array())
+
+	x, err := SolveSPD(A, []float64{4, 12, -16})
+	if err != nil {
+		t.Fatal(err)
+	}
+	utils.ExpectNear(t, 1, x[0], 1e-9)
+	utils.ExpectNear(t, 0, x[1], 1e-9)
+	utils.ExpectNear(t, 0, x[2], 1e-9)
+
+	_, err = SolveSPD(MakeDenseMatrixStacked([][]float64{[]float64{1, 2}, []float64{2, 1}}), []float64{1, 1})
+	utils.Expect(t, ExceptionNotSPD.Error(), err)
+
+	B := A.Copy()
+	if err := B.InverseSPD(); err != nil {
+		t.Fatal(err)
+	}
+	P, _ := A.Times(B)
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			utils.ExpectNear(t, Eye(3).Get(i, j), P.Get(i, j), 1e-9)
+		}
+	}
+}
+
+func TestInverse(t *testing.T) {
+	A := MakeDenseMatrixStacked([][]float64{[]float64{0, 2, 1}, []float64{1, 1, 0}, []float64{2, 0, 3}})
+	B, err := A.Inverse()
+	if err != nil {
+		t.Fatal(err)
+	}
+	P, _ := A.Times(B)
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			utils.ExpectNear(t, Eye(3).Get(i, j), P.Get(i, j), 1e-9)
+		}
+	}
+
+	_, err = MakeDenseMatrixStacked([][]float64{[]float64{1, 2}, []float64{2, 4}}).Inverse()
+	utils.Expect(t, ExceptionSingular.Error(), err)
+
+	S := MakeSparseCopy(MakeDenseMatrixStacked([][]float64{[]float64{1, 2}, []float64{3, 4}, []float64{5, 6}}))
+	utils.Expect(t, "[35 44 44 56]", S.Gram().Array())
+}
@@ -15,6 +15,7 @@ package math
 
 import (
 	"math/rand"
+	"sort"
 )
 
 type SparseMatrix struct {
@@ -54,7 +55,7 @@ func (M *SparseMatrix) GetRowIndex(index int) (i int) {
 	return
 }
 
-func (M *SparseMatrix) GetRowIndex(index int) (j int) {
+func (M *SparseMatrix) GetColIndex(index int) (j int) {
 	j = (index - M.offset) % M.step
 	return
 }
@@ -129,7 +130,7 @@ func (M *SparseMatrix) Indices() (out chan int) {
 }
 
 
-func (M *SparseMatrix) SubMatrix(i, j, row, cols int) *SparseMatrix {
+func (M *SparseMatrix) SubMatrix(i, j, rows, cols int) *SparseMatrix {
 	if i < 0 || j < 0 || i + rows > M.rows || j + cols > M.cols {
 		i = maxInt(0, i)
 		j = maxInt(0, j)
@@ -140,7 +141,7 @@ func (M *SparseMatrix) SubMatrix(i, j, row, cols int) *SparseMatrix {
 
 	for index, value := range M.elements {
 		r, c := M.GetRowColIndex(index)
-		if r < i + row && c < j + cols {
+		if r >= i && c >= j && r < i + rows && c < j + cols {
 			S.Set(r-i, c-j, value)
 		}
 	}
@@ -165,12 +166,12 @@ func (A *SparseMatrix) Augment(B *SparseMatrix) (*SparseMatrix, error) {
 	C := ZerosSparse(A.rows, A.cols + B.cols)
 
 	for index, value := range A.elements {
-		i, j = A.GetRowColIndex(index)
+		i, j := A.GetRowColIndex(index)
 		C.Set(i, j, value)
 	}
 
 	for index, value := range B.elements {
-		i, j = B.GetRowColIndex(index)
+		i, j := B.GetRowColIndex(index)
 		C.Set(i, j+A.cols, value)
 	}
 	
@@ -191,7 +192,7 @@ func (A *SparseMatrix) Stack(B *SparseMatrix) (*SparseMatrix, error) {
 
 	for index, value := range B.elements {
 		i, j := B.GetRowColIndex(index)
-		C.Set(i, j, value)
+		C.Set(i+A.rows, j, value)
 	}
 
 	return C, nil
@@ -231,12 +232,94 @@ func (M *SparseMatrix) Copy() *SparseMatrix {
 func (M *SparseMatrix) DenseMatrix() *DenseMatrix {
 	D := Zeros(M.rows, M.cols)
 	for index, value := range M.elements {
-		i, j = M.GetRowColInddex(index)
+		i, j := M.GetRowColIndex(index)
 		D.Set(i, j, value)
 	}
 	return D
 }
 
+// Returns a dense copy of the matrix as array of rows.
+// Unlike the dense matrix, changes to the slices do not effect the matrix.
+func (M *SparseMatrix) Arrays() [][]float64 {
+	return M.DenseMatrix().Arrays()
+}
+
+// Returns a dense copy of the matrix stored into a flat array (row-major).
+func (M *SparseMatrix) Array() []float64 {
+	return M.DenseMatrix().Array()
+}
+
+// Get the number of non-zero elements
+func (M *SparseMatrix) NumNonZeros() int {
+	n := 0
+	M.Each(func(i, j int, v float64) { n++ })
+	return n
+}
+
+// Call f on each non-zero element of the matrix in no particular order
+func (M *SparseMatrix) Each(f func(i, j int, v float64)) {
+	for index, value := range M.elements {
+		i, j := M.GetRowColIndex(index)
+		if 0 <= i && i < M.rows && 0 <= j && j < M.cols {
+			f(i, j, value)
+		}
+	}
+}
+
+// A non-zero element in a row or column of a sparse matrix
+type SparseEntry struct {
+	// The column index for a row, or the row index for a column
+	Index int
+
+	Value float64
+}
+
+// Get the value of the index in the entries ordered by index by binary
+// search, false if the index is not among them
+func LookupEntry(entries []SparseEntry, index int) (float64, bool) {
+	lo, hi := 0, len(entries)
+	for lo < hi {
+		mid := (lo + hi) / 2
+		if entries[mid].Index < index {
+			lo = mid + 1
+		} else {
+			hi = mid
+		}
+	}
+	if lo < len(entries) && entries[lo].Index == index {
+		return entries[lo].Value, true
+	}
+	return 0, false
+}
+
+// Get the non-zero elements of each row ordered by the column index
+func (M *SparseMatrix) RowEntries() [][]SparseEntry {
+	rows := make([][]SparseEntry, M.rows)
+	M.Each(func(i, j int, v float64) {
+		rows[i] = append(rows[i], SparseEntry{j, v})
+	})
+	for _, row := range rows {
+		sortEntries(row)
+	}
+	return rows
+}
+
+// Get the non-zero elements of each column ordered by the row index
+func (M *SparseMatrix) ColEntries() [][]SparseEntry {
+	cols := make([][]SparseEntry, M.cols)
+	M.Each(func(i, j int, v float64) {
+		cols[j] = append(cols[j], SparseEntry{i, v})
+	})
+	for _, col := range cols {
+		sortEntries(col)
+	}
+	return cols
+}
+
+func sortEntries(entries []SparseEntry) {
+	sort.Slice(entries, func(a, b int) bool { return entries[a].Index < entries[b].Index })
+}
+
 func (M *SparseMatrix) String() string {return String(M)}
 
 func ZerosSparse(rows, cols int) *SparseMatrix {
@@ -280,7 +363,7 @@ func NormalsSparse(rows, cols int) *SparseMatrix {
 	return N
 }
 
-func Diagonal(d []float64) *SparseMatrix {
+func DiagonalSparse(d []float64) *SparseMatrix {
 	n := len(d)
 	D := ZerosSparse(n, n)
 	for i := 0; i < n; i++ {
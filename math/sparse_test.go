@@ -0,0 +1,32 @@
+// Copyright (c) 2014 Feng Wang <wffrank1987@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language
+
+package math
+
+import (
+	"fmt"
+	"github.com/numb3r3/gorec/utils"
+	"testing"
+)
+
+func TestLookupEntry(t *testing.T) {
+	S := MakeSparseCopy(MakeDenseMatrixStacked([][]float64{[]float64{0, 2, 0, 3}, []float64{1, 0, 0, 0}}))
+	row := S.RowEntries()[0]
+	utils.Expect(t, "2", len(row))
+	for index, expect := range []string{"0 false", "2 true", "0 false", "3 true", "0 false"} {
+		v, ok := LookupEntry(row, index)
+		utils.Expect(t, expect, fmt.Sprint(v, " ", ok))
+	}
+	_, ok := LookupEntry(nil, 0)
+	utils.Expect(t, "false", ok)
+}
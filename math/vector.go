@@ -39,7 +39,7 @@ func NewVector(length int) *Vector {
 }
 
 // Creat a new sparse vector
-fun NewSparseVector() *Vector {
+func NewSparseVector() *Vector {
 	v := new(Vector)
 	v.sparse_values = make(map[int]float64)
 	v.isSparse = true
@@ -93,9 +93,13 @@ func (v *Vector) IsSparse() bool {
 
 func (v *Vector) Indexes() []int {
 	if v.isSparse {
-		return v.sparse_values.keys
+		indexes := make([]int, 0, len(v.sparse_values))
+		for i := range v.sparse_values {
+			indexes = append(indexes, i)
+		}
+		return indexes
 	} else {
-		indexes = make([]int, len(v.values))
+		indexes := make([]int, len(v.values))
 		for i := 0; i < len(v.values); i++ {
 			indexes[i] = i
 		}
@@ -165,11 +169,11 @@ func (v *Vector) SetValues(values []float64) {
 
 // v_i = v_i + alpha * o_i
 func (v *Vector) Increament(o *Vector, alpha float64) {
-	if !.v.IsHomogeneous(that) {
+	if !v.isHomogeneous(o) {
 		log.Fatal("cannot perform the increment opertion on two different type of vectors")
 	}
 	if v.isSparse {
-		for i, value := o.sparse_values {
+		for i, value := range o.sparse_values {
 			v.sparse_values[i] += value * alpha
 		}
 	} else {
@@ -215,7 +219,7 @@ func (v *Vector) Dot(o *Vector) float64 {
 	
 	var result float64
 	for i, k := range v.values {
-		result += k + o.values[i]
+		result += k * o.values[i]
 	}
 	return result
 }
@@ -32,3 +32,11 @@ func TestCopy(t *testing.T) {
 
 
 
+
+func TestDot(t *testing.T) {
+	va := NewVector(3)
+	va.SetValues([]float64{1, 2, 3})
+	vb := NewVector(3)
+	vb.SetValues([]float64{4, 5, 6})
+	utils.ExpectNear(t, 32, va.Dot(vb), 1e-12)
+}
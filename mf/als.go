@@ -0,0 +1,220 @@
+// Copyright (c) 2014 Feng Wang <wffrank1987@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language
+
+package mf
+
+import (
+	"math/rand"
+	"runtime"
+	"sync"
+
+	"github.com/numb3r3/gorec/core"
+	"github.com/numb3r3/gorec/math"
+)
+
+// The options of the implicit alternating least squares
+type ALSOptions struct {
+
+	// The number of latent factors
+	NumFactors int
+
+	// The number of alternating iterations
+	NumIterations int
+
+	// The confidence of an observed count r is 1 + Alpha * r
+	Alpha float64
+
+	// The L2 regularization of the factors
+	Regularization float64
+
+	// The standard deviation of the initial factors
+	InitStd float64
+
+	// The seed of the random number generator
+	Seed int64
+
+	// The number of goroutines solving the least squares, NumCPU if not positive
+	NumWorkers int
+
+	// The weights of the implicit events, nil for the default weights
+	Weights core.EventWeights
+}
+
+func DefaultALSOptions() ALSOptions {
+	return ALSOptions{
+		NumFactors:     20,
+		NumIterations:  15,
+		Alpha:          40,
+		Regularization: 0.1,
+		InitStd:        0.01,
+		Seed:           1,
+	}
+}
+
+// The weighted alternating least squares for implicit feedback
+// (Hu, Koren and Volinsky, 2008). The preference p_ui is 1 if the user
+// interacted with the item and 0 otherwise, weighted by the confidence
+// c_ui = 1 + alpha * r_ui.
+type ALS struct {
+	options ALSOptions
+
+	// users x factors and items x factors
+	X, Y *math.DenseMatrix
+
+	// The items of each user
+	userItems [][]math.SparseEntry
+}
+
+func NewALS(options ALSOptions) *ALS {
+	model := new(ALS)
+	model.options = options
+	return model
+}
+
+// Fit on the weighted interactions of the records
+func (m *ALS) Fit(records []*core.Record) error {
+	if len(records) == 0 {
+		return core.ErrorEmptyRecords
+	}
+	R, err := core.InteractionMatrix(records, m.options.Weights, 0, 0)
+	if err != nil {
+		return err
+	}
+	return m.Train(R)
+}
+
+// Train on the user x item matrix of the interaction counts
+func (m *ALS) Train(R *math.SparseMatrix) error {
+	if R.Rows() == 0 || R.Cols() == 0 {
+		return core.ErrorEmptyRecords
+	}
+	o := m.options
+	rng := rand.New(rand.NewSource(o.Seed))
+	m.X = math.RandomNormals(R.Rows(), o.NumFactors, o.InitStd, rng)
+	m.Y = math.RandomNormals(R.Cols(), o.NumFactors, o.InitStd, rng)
+	m.userItems = R.RowEntries()
+	itemUsers := R.ColEntries()
+
+	for iter := 0; iter < o.NumIterations; iter++ {
+		if err := m.solve(m.X, m.Y, m.userItems); err != nil {
+			return err
+		}
+		if err := m.solve(m.Y, m.X, itemUsers); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Solve each row of X with Y fixed:
+//
+//	x_u = (Y^T Y + Y^T (C_u - I) Y + lambda I)^-1 Y^T C_u p_u
+func (m *ALS) solve(X, Y *math.DenseMatrix, observed [][]math.SparseEntry) error {
+	o := m.options
+	k := o.NumFactors
+	YtY := Y.Gram()
+
+	var firstErr error
+	var mu sync.Mutex
+	parallelFor(X.Rows(), o.NumWorkers, func(u int) {
+		A := YtY.Copy()
+		b := make([]float64, k)
+		for f := 0; f < k; f++ {
+			A.Set(f, f, A.Get(f, f)+o.Regularization)
+		}
+		for _, e := range observed[u] {
+			c := 1 + o.Alpha*e.Value
+			y := Y.RowSlice(e.Index)
+			for f := 0; f < k; f++ {
+				row := A.RowSlice(f)
+				for g := 0; g < k; g++ {
+					row[g] += (c - 1) * y[f] * y[g]
+				}
+				b[f] += c * y[f]
+			}
+		}
+		x, err := math.SolveSPD(A, b)
+		if err != nil {
+			mu.Lock()
+			if firstErr == nil {
+				firstErr = err
+			}
+			mu.Unlock()
+			return
+		}
+		copy(X.RowSlice(u), x)
+	})
+	return firstErr
+}
+
+// Call f(i) for i in [0, n) with the workers goroutines (NumCPU if not positive)
+func parallelFor(n, workers int, f func(i int)) {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				f(i)
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+// Predict the preference of the user on the item
+func (m *ALS) Predict(userId, item int) float64 {
+	if m.X == nil || userId < 0 || userId >= m.X.Rows() || item < 0 || item >= m.Y.Rows() {
+		return 0
+	}
+	return math.DotSlices(m.X.RowSlice(userId), m.Y.RowSlice(item))
+}
+
+// Recommend the items with the highest preferences which the user has not interacted with
+func (m *ALS) Recommend(userId int, context *core.Context, n int) []*core.ScoredProduct {
+	if m.X == nil || userId < 0 || userId >= m.X.Rows() {
+		return nil
+	}
+	scores := make([]float64, m.Y.Rows())
+	for i := range scores {
+		scores[i] = m.Predict(userId, i)
+	}
+	seen := make(map[int]bool)
+	for _, e := range m.userItems[userId] {
+		seen[e.Index] = true
+	}
+	return core.TopNSlice(scores, n, seen)
+}
+
+// Get the users x factors matrix
+func (m *ALS) UserFactors() *math.DenseMatrix {
+	return m.X
+}
+
+// Get the items x factors matrix
+func (m *ALS) ItemFactors() *math.DenseMatrix {
+	return m.Y
+}
+
+// Get the n items most similar to the item by the cosine of the factors
+func (m *ALS) SimilarItems(item, n int) []*core.ScoredProduct {
+	return similarRows(m.Y, item, n)
+}
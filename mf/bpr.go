@@ -0,0 +1,214 @@
+// Copyright (c) 2014 Feng Wang <wffrank1987@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language
+
+package mf
+
+import (
+	gomath "math"
+	"math/rand"
+
+	"github.com/numb3r3/gorec/core"
+	"github.com/numb3r3/gorec/math"
+)
+
+// The options of the Bayesian personalized ranking
+type BPROptions struct {
+
+	// The number of latent factors
+	NumFactors int
+
+	// The number of passes, each pass draws as many triples as interactions
+	NumEpochs int
+
+	// The learning rate of SGD
+	LearningRate float64
+
+	// The L2 regularization of the factors
+	Regularization float64
+
+	// The L2 regularization of the item biases
+	BiasRegularization float64
+
+	// The standard deviation of the initial factors
+	InitStd float64
+
+	// The seed of the random number generator, the training is
+	// deterministic for a given seed
+	Seed int64
+
+	// The sampler of the negative items, uniform if nil
+	Sampler NegativeSampler
+
+	// The weights of the implicit events, nil for the default weights
+	Weights core.EventWeights
+}
+
+func DefaultBPROptions() BPROptions {
+	return BPROptions{
+		NumFactors:         20,
+		NumEpochs:          30,
+		LearningRate:       0.05,
+		Regularization:     0.01,
+		BiasRegularization: 0.01,
+		InitStd:            0.1,
+		Seed:               1,
+	}
+}
+
+// The maximum number of draws to find a negative item for a user
+const maxNegativeTries = 100
+
+// The matrix factorization optimized by Bayesian personalized ranking
+// (Rendle et al., 2009). For a user u, an interacted item i and a
+// non-interacted item j, it maximizes ln sigmoid(x_ui - x_uj) with
+//
+//	x_ui = b_i + p_u . q_i
+type BPR struct {
+	options BPROptions
+
+	// users x factors and items x factors
+	P, Q *math.DenseMatrix
+
+	itemBias []float64
+
+	// The items of each user
+	userItems [][]math.SparseEntry
+}
+
+func NewBPR(options BPROptions) *BPR {
+	model := new(BPR)
+	model.options = options
+	return model
+}
+
+// Fit on the weighted interactions of the records
+func (m *BPR) Fit(records []*core.Record) error {
+	if len(records) == 0 {
+		return core.ErrorEmptyRecords
+	}
+	R, err := core.InteractionMatrix(records, m.options.Weights, 0, 0)
+	if err != nil {
+		return err
+	}
+	return m.Train(R)
+}
+
+// Train on the user x item implicit interaction matrix
+func (m *BPR) Train(R *math.SparseMatrix) error {
+	if R.Rows() == 0 || R.Cols() == 0 {
+		return core.ErrorEmptyRecords
+	}
+	o := m.options
+	rng := rand.New(rand.NewSource(o.Seed))
+	sampler := o.Sampler
+	if sampler == nil {
+		sampler = NewUniformSampler()
+	}
+	sampler.Init(R)
+
+	m.P = math.RandomNormals(R.Rows(), o.NumFactors, o.InitStd, rng)
+	m.Q = math.RandomNormals(R.Cols(), o.NumFactors, o.InitStd, rng)
+	m.itemBias = make([]float64, R.Cols())
+	m.userItems = R.RowEntries()
+
+	// the positive (user, item) pairs
+	var users, items []int
+	for u, entries := range m.userItems {
+		for _, e := range entries {
+			users = append(users, u)
+			items = append(items, e.Index)
+		}
+	}
+	if len(users) == 0 {
+		return core.ErrorEmptyRecords
+	}
+
+	for epoch := 0; epoch < o.NumEpochs; epoch++ {
+		for n := 0; n < len(users); n++ {
+			k := rng.Intn(len(users))
+			u, i := users[k], items[k]
+			j, ok := m.sampleNegative(u, sampler, rng)
+			if !ok {
+				continue
+			}
+			m.update(u, i, j)
+		}
+	}
+	return nil
+}
+
+func (m *BPR) sampleNegative(u int, sampler NegativeSampler, rng *rand.Rand) (int, bool) {
+	for t := 0; t < maxNegativeTries; t++ {
+		j := sampler.Sample(u, rng)
+		if _, positive := math.LookupEntry(m.userItems[u], j); !positive {
+			return j, true
+		}
+	}
+	return 0, false
+}
+
+// One SGD step on the triple (u, i, j)
+func (m *BPR) update(u, i, j int) {
+	o := m.options
+	pu, qi, qj := m.P.RowSlice(u), m.Q.RowSlice(i), m.Q.RowSlice(j)
+	x := m.itemBias[i] - m.itemBias[j] + math.DotSlices(pu, qi) - math.DotSlices(pu, qj)
+	g := 1 / (1 + gomath.Exp(x))
+
+	m.itemBias[i] += o.LearningRate * (g - o.BiasRegularization*m.itemBias[i])
+	m.itemBias[j] += o.LearningRate * (-g - o.BiasRegularization*m.itemBias[j])
+	for f := range pu {
+		p, a, b := pu[f], qi[f], qj[f]
+		pu[f] += o.LearningRate * (g*(a-b) - o.Regularization*p)
+		qi[f] += o.LearningRate * (g*p - o.Regularization*a)
+		qj[f] += o.LearningRate * (-g*p - o.Regularization*b)
+	}
+}
+
+// Get the ranking score of the user on the item
+func (m *BPR) Predict(userId, item int) float64 {
+	if m.P == nil || userId < 0 || userId >= m.P.Rows() || item < 0 || item >= m.Q.Rows() {
+		return 0
+	}
+	return m.itemBias[item] + math.DotSlices(m.P.RowSlice(userId), m.Q.RowSlice(item))
+}
+
+// Recommend the items with the highest scores which the user has not interacted with
+func (m *BPR) Recommend(userId int, context *core.Context, n int) []*core.ScoredProduct {
+	if m.P == nil || userId < 0 || userId >= m.P.Rows() {
+		return nil
+	}
+	scores := make([]float64, m.Q.Rows())
+	for i := range scores {
+		scores[i] = m.Predict(userId, i)
+	}
+	seen := make(map[int]bool)
+	for _, e := range m.userItems[userId] {
+		seen[e.Index] = true
+	}
+	return core.TopNSlice(scores, n, seen)
+}
+
+// Get the users x factors matrix
+func (m *BPR) UserFactors() *math.DenseMatrix {
+	return m.P
+}
+
+// Get the items x factors matrix
+func (m *BPR) ItemFactors() *math.DenseMatrix {
+	return m.Q
+}
+
+// Get the item biases
+func (m *BPR) ItemBiases() []float64 {
+	return m.itemBias
+}
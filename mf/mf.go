@@ -0,0 +1,295 @@
+// Copyright (c) 2014 Feng Wang <wffrank1987@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language
+
+package mf
+
+import (
+	gomath "math"
+	"math/rand"
+	"time"
+
+	"github.com/numb3r3/gorec/core"
+	"github.com/numb3r3/gorec/data"
+	"github.com/numb3r3/gorec/math"
+)
+
+// The options of the biased matrix factorization
+type MFOptions struct {
+
+	// The number of latent factors
+	NumFactors int
+
+	// The number of passes over the ratings
+	NumEpochs int
+
+	// The initial learning rate of SGD
+	LearningRate float64
+
+	// The learning rate is multiplied by Decay after each epoch
+	Decay float64
+
+	// The L2 regularization of the factors
+	Regularization float64
+
+	// The L2 regularization of the user and item biases
+	BiasRegularization float64
+
+	// Whether to learn the global mean and the biases, the model is the
+	// plain Funk SVD if false
+	UseBiases bool
+
+	// The standard deviation of the initial factors
+	InitStd float64
+
+	// The seed of the random number generator
+	Seed int64
+}
+
+func DefaultMFOptions() MFOptions {
+	return MFOptions{
+		NumFactors:         20,
+		NumEpochs:          20,
+		LearningRate:       0.01,
+		Decay:              0.95,
+		Regularization:     0.02,
+		BiasRegularization: 0.02,
+		UseBiases:          true,
+		InitStd:            0.1,
+		Seed:               1,
+	}
+}
+
+// One observed rating
+type rating struct {
+	user, item int
+	value      float64
+}
+
+// The biased matrix factorization trained by SGD. The predicted rating is
+//
+//	r_ui = mu + b_u + b_i + p_u . q_i
+type MF struct {
+	options MFOptions
+
+	globalMean float64
+
+	userBias, itemBias []float64
+
+	// users x factors and items x factors
+	P, Q *math.DenseMatrix
+
+	// The items rated by each user
+	userItems []map[int]bool
+}
+
+func NewMF(options MFOptions) *MF {
+	model := new(MF)
+	model.options = options
+	return model
+}
+
+// Fit on the explicit ratings of the records, the latest rating wins if
+// a user rated an item several times
+func (m *MF) Fit(records []*core.Record) error {
+	return m.train(latestRatings(records))
+}
+
+// Train on the user x item rating matrix
+func (m *MF) Train(R *math.SparseMatrix) error {
+	ratings := make([]rating, 0)
+	R.Each(func(i, j int, v float64) {
+		ratings = append(ratings, rating{i, j, v})
+	})
+	return m.trainWithDimension(ratings, R.Rows(), R.Cols())
+}
+
+// Train on the explicit ratings of the dataset whose instances are made
+// by core.Record.ToInstance
+func (m *MF) TrainDataset(dataset data.Dataset) error {
+	return m.Fit(core.DatasetRecords(dataset))
+}
+
+// Get the explicit ratings of the records in order, keeping the latest
+// rating of each user and item
+func latestRatings(records []*core.Record) []rating {
+	var ratings []rating
+	// user and item -> the index of the latest rating, and its time
+	latest := make(map[[2]int]int)
+	times := make(map[[2]int]time.Time)
+	for _, r := range records {
+		if !r.IsExplicit() {
+			continue
+		}
+		key := [2]int{r.UserId, r.ProductId}
+		i, ok := latest[key]
+		if !ok {
+			latest[key], times[key] = len(ratings), r.Timestamp
+			ratings = append(ratings, rating{r.UserId, r.ProductId, r.Value})
+			continue
+		}
+		if !r.Timestamp.Before(times[key]) {
+			ratings[i].value, times[key] = r.Value, r.Timestamp
+		}
+	}
+	return ratings
+}
+
+// Train on the ratings, ErrorIllegalId is returned for a negative id
+func (m *MF) train(ratings []rating) error {
+	rows, cols := 0, 0
+	for _, r := range ratings {
+		if r.user < 0 || r.item < 0 {
+			return core.ErrorIllegalId
+		}
+		if r.user >= rows {
+			rows = r.user + 1
+		}
+		if r.item >= cols {
+			cols = r.item + 1
+		}
+	}
+	return m.trainWithDimension(ratings, rows, cols)
+}
+
+func (m *MF) trainWithDimension(ratings []rating, rows, cols int) error {
+	if len(ratings) == 0 {
+		return core.ErrorEmptyRecords
+	}
+	o := m.options
+	rng := rand.New(rand.NewSource(o.Seed))
+
+	m.P = math.RandomNormals(rows, o.NumFactors, o.InitStd, rng)
+	m.Q = math.RandomNormals(cols, o.NumFactors, o.InitStd, rng)
+	m.userBias = make([]float64, rows)
+	m.itemBias = make([]float64, cols)
+	m.userItems = make([]map[int]bool, rows)
+	m.globalMean = 0
+	for _, r := range ratings {
+		if m.userItems[r.user] == nil {
+			m.userItems[r.user] = make(map[int]bool)
+		}
+		m.userItems[r.user][r.item] = true
+		m.globalMean += r.value
+	}
+	m.globalMean /= float64(len(ratings))
+	if !o.UseBiases {
+		m.globalMean = 0
+	}
+
+	order := rng.Perm(len(ratings))
+	lr := o.LearningRate
+	for epoch := 0; epoch < o.NumEpochs; epoch++ {
+		for i := len(order) - 1; i > 0; i-- {
+			j := rng.Intn(i + 1)
+			order[i], order[j] = order[j], order[i]
+		}
+		for _, idx := range order {
+			r := ratings[idx]
+			err := r.value - m.Predict(r.user, r.item)
+			if o.UseBiases {
+				m.userBias[r.user] += lr * (err - o.BiasRegularization*m.userBias[r.user])
+				m.itemBias[r.item] += lr * (err - o.BiasRegularization*m.itemBias[r.item])
+			}
+			pu, qi := m.P.RowSlice(r.user), m.Q.RowSlice(r.item)
+			for f := range pu {
+				p, q := pu[f], qi[f]
+				pu[f] += lr * (err*q - o.Regularization*p)
+				qi[f] += lr * (err*p - o.Regularization*q)
+			}
+		}
+		lr *= o.Decay
+	}
+	return nil
+}
+
+// Predict the rating of the user on the item. The factors and the bias of
+// an unknown user or item are regarded as zero.
+func (m *MF) Predict(userId, item int) float64 {
+	score := m.globalMean
+	knownUser := userId >= 0 && userId < len(m.userBias)
+	knownItem := item >= 0 && item < len(m.itemBias)
+	if knownUser {
+		score += m.userBias[userId]
+	}
+	if knownItem {
+		score += m.itemBias[item]
+	}
+	if knownUser && knownItem {
+		score += math.DotSlices(m.P.RowSlice(userId), m.Q.RowSlice(item))
+	}
+	return score
+}
+
+// Recommend the items with the highest predicted ratings which the user has not rated
+func (m *MF) Recommend(userId int, context *core.Context, n int) []*core.ScoredProduct {
+	if m.Q == nil {
+		return nil
+	}
+	scores := make([]float64, m.Q.Rows())
+	for i := range scores {
+		scores[i] = m.Predict(userId, i)
+	}
+	var seen map[int]bool
+	if userId >= 0 && userId < len(m.userItems) {
+		seen = m.userItems[userId]
+	}
+	return core.TopNSlice(scores, n, seen)
+}
+
+// Get the global mean of the ratings
+func (m *MF) GlobalMean() float64 {
+	return m.globalMean
+}
+
+// Get the user biases
+func (m *MF) UserBiases() []float64 {
+	return m.userBias
+}
+
+// Get the item biases
+func (m *MF) ItemBiases() []float64 {
+	return m.itemBias
+}
+
+// Get the users x factors matrix
+func (m *MF) UserFactors() *math.DenseMatrix {
+	return m.P
+}
+
+// Get the items x factors matrix
+func (m *MF) ItemFactors() *math.DenseMatrix {
+	return m.Q
+}
+
+// Get the n items most similar to the item by the cosine of the factors
+func (m *MF) SimilarItems(item, n int) []*core.ScoredProduct {
+	return similarRows(m.Q, item, n)
+}
+
+// Get the n rows most similar to the row by cosine, excluding the row itself
+func similarRows(M *math.DenseMatrix, row, n int) []*core.ScoredProduct {
+	if M == nil || row < 0 || row >= M.Rows() {
+		return nil
+	}
+	target := M.RowSlice(row)
+	tn := gomath.Sqrt(math.DotSlices(target, target))
+	scores := make([]float64, M.Rows())
+	for i := range scores {
+		v := M.RowSlice(i)
+		vn := gomath.Sqrt(math.DotSlices(v, v))
+		if tn > 0 && vn > 0 {
+			scores[i] = math.DotSlices(target, v) / (tn * vn)
+		}
+	}
+	return core.TopNSlice(scores, n, map[int]bool{row: true})
+}
@@ -0,0 +1,141 @@
+// Copyright (c) 2014 Feng Wang <wffrank1987@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language
+
+package mf
+
+import (
+	"fmt"
+	gomath "math"
+	"testing"
+	"time"
+
+	"github.com/numb3r3/gorec/core"
+	"github.com/numb3r3/gorec/utils"
+)
+
+func ratingRecords() []*core.Record {
+	values := [][]float64{
+		{5, 4, 0, 1},
+		{4, 5, 1, 0},
+		{1, 0, 5, 4},
+		{0, 1, 4, 5},
+	}
+	var records []*core.Record
+	for u, row := range values {
+		for i, v := range row {
+			if v != 0 {
+				records = append(records, &core.Record{UserId: u, ProductId: i, Value: v})
+			}
+		}
+	}
+	return records
+}
+
+func TestMF(t *testing.T) {
+	options := DefaultMFOptions()
+	options.NumFactors = 4
+	options.NumEpochs = 500
+	options.LearningRate = 0.05
+	options.Decay = 1
+	model := NewMF(options)
+	if err := model.TrainDataset(core.RecordsDataset(ratingRecords())); err != nil {
+		t.Fatal(err)
+	}
+
+	var se float64
+	records := ratingRecords()
+	for _, r := range records {
+		e := model.Predict(r.UserId, r.ProductId) - r.Value
+		se += e * e
+	}
+	rmse := gomath.Sqrt(se / float64(len(records)))
+	utils.Expect(t, "true", rmse < 0.3)
+	utils.Expect(t, "4", model.ItemFactors().Cols())
+	utils.Expect(t, "1", model.SimilarItems(0, 1)[0].ProductId)
+
+	err := NewMF(options).Fit([]*core.Record{core.NewRating(-1, 0, 5, time.Time{})})
+	utils.Expect(t, core.ErrorIllegalId.Error(), err)
+}
+
+func TestMFRatings(t *testing.T) {
+	options := DefaultMFOptions()
+	expected := NewMF(options)
+	expected.Fit(ratingRecords())
+
+	// the implicit events are not ratings, and only the latest rating
+	// counts, the later record wins the tie
+	records := []*core.Record{core.NewRating(0, 0, 1, time.Time{})}
+	records = append(records, ratingRecords()...)
+	records = append(records,
+		core.NewEvent(0, 0, core.EventPurchase, time.Time{}),
+		core.NewEvent(9, 9, core.EventView, time.Time{}),
+		core.NewRating(0, 1, 4, time.Date(2014, 1, 1, 0, 0, 0, 0, time.UTC)),
+		core.NewRating(0, 1, 1, time.Date(2013, 1, 1, 0, 0, 0, 0, time.UTC)))
+	model := NewMF(options)
+	if err := model.TrainDataset(core.RecordsDataset(records)); err != nil {
+		t.Fatal(err)
+	}
+	utils.Expect(t, fmt.Sprint(expected.ItemFactors().Rows()), model.ItemFactors().Rows())
+	for _, r := range ratingRecords() {
+		utils.ExpectNear(t, expected.Predict(r.UserId, r.ProductId), model.Predict(r.UserId, r.ProductId), 1e-12)
+	}
+}
+
+func TestALS(t *testing.T) {
+	// two groups of users sharing two groups of items
+	var records []*core.Record
+	for u := 0; u < 6; u++ {
+		for i := 0; i < 3; i++ {
+			if (u+i)%3 != 0 {
+				records = append(records, core.NewEvent(u, i+3*(u%2), core.EventClick, time.Time{}))
+			}
+		}
+	}
+	options := DefaultALSOptions()
+	options.NumFactors = 2
+	model := NewALS(options)
+	if err := model.Fit(records); err != nil {
+		t.Fatal(err)
+	}
+	// the unseen item of user 0 is in the group of its own
+	top := model.Recommend(0, nil, 1)
+	utils.Expect(t, "0", top[0].ProductId)
+	utils.Expect(t, "true", model.Predict(0, 0) > model.Predict(0, 3))
+}
+
+func TestBPR(t *testing.T) {
+	var records []*core.Record
+	for u := 0; u < 6; u++ {
+		for i := 0; i < 3; i++ {
+			if (u+i)%3 != 0 {
+				records = append(records, core.NewEvent(u, i+3*(u%2), core.EventClick, time.Time{}))
+			}
+		}
+	}
+	for _, sampler := range []NegativeSampler{NewUniformSampler(), NewPopularitySampler(0.75)} {
+		options := DefaultBPROptions()
+		options.NumFactors = 4
+		options.NumEpochs = 200
+		options.Sampler = sampler
+		model := NewBPR(options)
+		if err := model.Fit(records); err != nil {
+			t.Fatal(err)
+		}
+		utils.Expect(t, "0", model.Recommend(0, nil, 1)[0].ProductId)
+
+		// the same seed gives the same model
+		again := NewBPR(options)
+		again.Fit(records)
+		utils.Expect(t, fmt.Sprint(model.Predict(0, 0)), again.Predict(0, 0))
+	}
+}
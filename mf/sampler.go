@@ -0,0 +1,86 @@
+// Copyright (c) 2014 Feng Wang <wffrank1987@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language
+
+package mf
+
+import (
+	gomath "math"
+	"math/rand"
+	"sort"
+
+	"github.com/numb3r3/gorec/math"
+)
+
+// The sampler of the negative items for pairwise ranking
+type NegativeSampler interface {
+
+	// Prepare the sampler on the user x item interaction matrix
+	Init(R *math.SparseMatrix)
+
+	// Draw a candidate negative item for the user, the caller rejects
+	// the items the user has interacted with
+	Sample(userId int, rng *rand.Rand) int
+}
+
+// Draw the items uniformly
+type UniformSampler struct {
+	numItems int
+}
+
+func NewUniformSampler() *UniformSampler {
+	return new(UniformSampler)
+}
+
+func (s *UniformSampler) Init(R *math.SparseMatrix) {
+	s.numItems = R.Cols()
+}
+
+func (s *UniformSampler) Sample(userId int, rng *rand.Rand) int {
+	return rng.Intn(s.numItems)
+}
+
+// Draw the items with probability proportional to popularity^exponent,
+// so that the popular items are more often used as negatives
+type PopularitySampler struct {
+	exponent float64
+
+	// cumulative[i] is the total weight of the items [0, i]
+	cumulative []float64
+}
+
+func NewPopularitySampler(exponent float64) *PopularitySampler {
+	s := new(PopularitySampler)
+	s.exponent = exponent
+	return s
+}
+
+func (s *PopularitySampler) Init(R *math.SparseMatrix) {
+	counts := make([]float64, R.Cols())
+	R.Each(func(i, j int, v float64) { counts[j]++ })
+	s.cumulative = make([]float64, len(counts))
+	var total float64
+	for i, c := range counts {
+		// every item keeps a chance to be drawn
+		total += gomath.Pow(c+1, s.exponent)
+		s.cumulative[i] = total
+	}
+}
+
+func (s *PopularitySampler) Sample(userId int, rng *rand.Rand) int {
+	target := rng.Float64() * s.cumulative[len(s.cumulative)-1]
+	i := sort.SearchFloat64s(s.cumulative, target)
+	if i >= len(s.cumulative) {
+		i = len(s.cumulative) - 1
+	}
+	return i
+}
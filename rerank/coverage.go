@@ -0,0 +1,97 @@
+// Copyright (c) 2014 Feng Wang <wffrank1987@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language
+
+package rerank
+
+import (
+	"github.com/numb3r3/gorec/core"
+)
+
+// The category coverage constraints. The candidates are picked by score
+// with at most MaxPerCategory of each category. If Level is not negative,
+// a product counts for its ancestor category at that depth of the taxonomy
+// instead of its own category. The skipped candidates fill up the list when
+// there are not enough categories. The list is in the order of selection.
+type CategoryCoverage struct {
+	Catalog *core.Catalog
+
+	MaxPerCategory int
+
+	Level int
+
+	// Whether to first pick the best candidate of every category
+	CoverFirst bool
+}
+
+func NewCategoryCoverage(catalog *core.Catalog, maxPerCategory int) *CategoryCoverage {
+	return &CategoryCoverage{Catalog: catalog, MaxPerCategory: maxPerCategory, Level: -1}
+}
+
+// Get the category the product counts for, empty if it has none
+func (r *CategoryCoverage) category(id int) string {
+	p := r.Catalog.GetProduct(id)
+	if p == nil {
+		return ""
+	}
+	if p.Category == "" || r.Level < 0 {
+		return p.Category
+	}
+	c := r.Catalog.GetTaxonomy().GetCategory(p.Category)
+	if c == nil {
+		return p.Category
+	}
+	for c.Depth() > r.Level {
+		c = c.Parent
+	}
+	return c.Name
+}
+
+func (r *CategoryCoverage) Rerank(candidates []*core.ScoredProduct, n int) []*core.ScoredProduct {
+	sorted := make([]*core.ScoredProduct, len(candidates))
+	copy(sorted, candidates)
+	core.SortScoredProducts(sorted)
+	n = clamp(n, len(sorted))
+	categories := make([]string, len(sorted))
+	for i, p := range sorted {
+		categories[i] = r.category(p.ProductId)
+	}
+
+	counts := make(map[string]int)
+	picked := make([]bool, len(sorted))
+	var result []*core.ScoredProduct
+	pick := func(i int) {
+		picked[i] = true
+		counts[categories[i]]++
+		result = append(result, sorted[i])
+	}
+	if r.CoverFirst {
+		for i := range sorted {
+			if len(result) < n && categories[i] != "" && counts[categories[i]] == 0 {
+				pick(i)
+			}
+		}
+	}
+	for i := range sorted {
+		// the products without category are not constrained
+		if len(result) < n && !picked[i] &&
+			(categories[i] == "" || r.MaxPerCategory <= 0 || counts[categories[i]] < r.MaxPerCategory) {
+			pick(i)
+		}
+	}
+	for i := range sorted {
+		if len(result) < n && !picked[i] {
+			pick(i)
+		}
+	}
+	return result
+}
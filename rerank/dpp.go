@@ -0,0 +1,98 @@
+// Copyright (c) 2014 Feng Wang <wffrank1987@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language
+
+package rerank
+
+import (
+	gomath "math"
+
+	"github.com/numb3r3/gorec/core"
+)
+
+// The greedy MAP inference of a determinantal point process (Chen et al.,
+// 2018). The kernel is L_ij = q_i * S_ij * q_j with the quality
+// q_i = exp(alpha * relevance_i), alpha = theta / (2 * (1 - theta)), so the
+// picked set maximizes theta * sum relevance + (1 - theta) * log det S.
+// The candidates left when the kernel becomes singular follow by relevance.
+type DPP struct {
+	Similarity ItemSimilarity
+
+	// The tradeoff in [0, 1), 0 for the diversity only
+	Theta float64
+
+	// Stop picking by the kernel when the marginal gain drops below Epsilon
+	Epsilon float64
+}
+
+func NewDPP(similarity ItemSimilarity, theta float64) *DPP {
+	return &DPP{similarity, theta, 1e-10}
+}
+
+func (r *DPP) Rerank(candidates []*core.ScoredProduct, n int) []*core.ScoredProduct {
+	sorted, relevance := normalizedRelevance(candidates)
+	m := len(sorted)
+	n = clamp(n, m)
+	alpha := r.Theta / (2 * (1 - r.Theta))
+	quality := make([]float64, m)
+	for i := range quality {
+		quality[i] = gomath.Exp(alpha * relevance[i])
+	}
+	kernel := func(i, j int) float64 {
+		s := 1.0
+		if i != j {
+			s = r.Similarity(sorted[i].ProductId, sorted[j].ProductId)
+		}
+		return quality[i] * s * quality[j]
+	}
+
+	// the incremental Cholesky rows c_i and the squared gains d_i
+	c := make([][]float64, m)
+	d := make([]float64, m)
+	for i := range d {
+		d[i] = kernel(i, i)
+	}
+	picked := make([]bool, m)
+	result := make([]*core.ScoredProduct, 0, n)
+	for len(result) < n {
+		j := -1
+		for i := range d {
+			if !picked[i] && (j < 0 || d[i] > d[j]) {
+				j = i
+			}
+		}
+		if d[j] < r.Epsilon {
+			break
+		}
+		picked[j] = true
+		result = append(result, sorted[j])
+		dj := gomath.Sqrt(d[j])
+		for i := range d {
+			if picked[i] {
+				continue
+			}
+			e := kernel(j, i)
+			for k, cj := range c[j] {
+				e -= cj * c[i][k]
+			}
+			e /= dj
+			c[i] = append(c[i], e)
+			d[i] -= e * e
+		}
+	}
+	for i := 0; i < m && len(result) < n; i++ {
+		if !picked[i] {
+			result = append(result, sorted[i])
+		}
+	}
+	return result
+}
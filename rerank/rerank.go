@@ -0,0 +1,128 @@
+// Copyright (c) 2014 Feng Wang <wffrank1987@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language
+
+package rerank
+
+import (
+	gomath "math"
+
+	"github.com/numb3r3/gorec/core"
+)
+
+// A re-ranking stage of the scored candidates. It picks n of the candidates
+// in the new order, keeping their original scores.
+type Reranker interface {
+	Rerank(candidates []*core.ScoredProduct, n int) []*core.ScoredProduct
+}
+
+// A recommender re-ranking the candidates of another recommender
+type RerankedRecommender struct {
+	recommender core.Recommender
+
+	reranker Reranker
+
+	// The number of candidates requested from the recommender
+	depth int
+}
+
+func NewRerankedRecommender(recommender core.Recommender, reranker Reranker, depth int) *RerankedRecommender {
+	return &RerankedRecommender{recommender, reranker, depth}
+}
+
+func (r *RerankedRecommender) Fit(records []*core.Record) error {
+	return r.recommender.Fit(records)
+}
+
+func (r *RerankedRecommender) Recommend(userId int, context *core.Context, n int) []*core.ScoredProduct {
+	depth := r.depth
+	if depth < n {
+		depth = n
+	}
+	return r.reranker.Rerank(r.recommender.Recommend(userId, context, depth), n)
+}
+
+// Sort a copy of the candidates and get their min-max normalized scores
+func normalizedRelevance(candidates []*core.ScoredProduct) ([]*core.ScoredProduct, []float64) {
+	sorted := make([]*core.ScoredProduct, len(candidates))
+	copy(sorted, candidates)
+	core.SortScoredProducts(sorted)
+	relevance := make([]float64, len(sorted))
+	if len(sorted) == 0 {
+		return sorted, relevance
+	}
+	max, min := sorted[0].Score, sorted[len(sorted)-1].Score
+	for i, p := range sorted {
+		if max > min {
+			relevance[i] = (p.Score - min) / (max - min)
+		} else {
+			relevance[i] = 1
+		}
+	}
+	return sorted, relevance
+}
+
+// Limit the number of the picked candidates to 0..m
+func clamp(n, m int) int {
+	if n < 0 {
+		return 0
+	}
+	if n > m {
+		return m
+	}
+	return n
+}
+
+// Maximal Marginal Relevance (Carbonell and Goldstein, 1998). It picks
+// greedily the candidate maximizing
+//
+//	lambda * relevance - (1 - lambda) * max similarity to the picked ones
+//
+// on the min-max normalized scores. Lambda 1 keeps the original order.
+type MMR struct {
+	Similarity ItemSimilarity
+
+	Lambda float64
+}
+
+func NewMMR(similarity ItemSimilarity, lambda float64) *MMR {
+	return &MMR{similarity, lambda}
+}
+
+func (r *MMR) Rerank(candidates []*core.ScoredProduct, n int) []*core.ScoredProduct {
+	sorted, relevance := normalizedRelevance(candidates)
+	n = clamp(n, len(sorted))
+	// the max similarity of each candidate to the picked ones
+	redundancy := make([]float64, len(sorted))
+	picked := make([]bool, len(sorted))
+	result := make([]*core.ScoredProduct, 0, n)
+	for len(result) < n {
+		best, bestValue := -1, gomath.Inf(-1)
+		for i := range sorted {
+			if picked[i] {
+				continue
+			}
+			value := r.Lambda*relevance[i] - (1-r.Lambda)*redundancy[i]
+			if value > bestValue {
+				best, bestValue = i, value
+			}
+		}
+		picked[best] = true
+		result = append(result, sorted[best])
+		for i := range sorted {
+			if !picked[i] {
+				redundancy[i] = gomath.Max(redundancy[i], r.Similarity(sorted[i].ProductId, sorted[best].ProductId))
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,88 @@
+// Copyright (c) 2014 Feng Wang <wffrank1987@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language
+
+package rerank
+
+import (
+	"fmt"
+	"github.com/numb3r3/gorec/core"
+	"github.com/numb3r3/gorec/utils"
+	"testing"
+)
+
+// Products 0-2 are phones, 3-4 are laptops and 5 is a tablet
+func catalog() *core.Catalog {
+	taxonomy := core.NewTaxonomy()
+	taxonomy.AddCategory("electronics", "")
+	taxonomy.AddCategory("phones", "electronics")
+	taxonomy.AddCategory("computers", "electronics")
+	taxonomy.AddCategory("laptops", "computers")
+	taxonomy.AddCategory("tablets", "computers")
+	c := core.NewCatalog(taxonomy)
+	for id, category := range []string{"phones", "phones", "phones", "laptops", "laptops", "tablets"} {
+		p := core.NewProduct(id, fmt.Sprint("product", id))
+		p.Category = category
+		c.AddProduct(p)
+	}
+	return c
+}
+
+func candidates() []*core.ScoredProduct {
+	scores := []float64{1, 0.95, 0.9, 0.8, 0.75, 0.5}
+	products := make([]*core.ScoredProduct, len(scores))
+	for i, score := range scores {
+		products[i] = &core.ScoredProduct{ProductId: i, Score: score}
+	}
+	return products
+}
+
+func ids(products []*core.ScoredProduct) string {
+	s := ""
+	for _, p := range products {
+		s += fmt.Sprint(p.ProductId, " ")
+	}
+	return s
+}
+
+func TestCategorySimilarity(t *testing.T) {
+	similarity := CategorySimilarity(catalog())
+	utils.Expect(t, "1 0.3333333333333333 0.6666666666666666",
+		fmt.Sprint(similarity(0, 1), similarity(0, 3), similarity(3, 5)))
+}
+
+func TestMMR(t *testing.T) {
+	similarity := CategorySimilarity(catalog())
+	utils.Expect(t, "0 1 2 ", ids(NewMMR(similarity, 1).Rerank(candidates(), 3)))
+	utils.Expect(t, "0 3 5 ", ids(NewMMR(similarity, 0.2).Rerank(candidates(), 3)))
+	utils.Expect(t, "", ids(NewMMR(similarity, 0.2).Rerank(candidates(), -1)))
+}
+
+func TestDPP(t *testing.T) {
+	similarity := CategorySimilarity(catalog())
+	utils.Expect(t, "0 3 5 ", ids(NewDPP(similarity, 0.5).Rerank(candidates(), 3)))
+	// the kernel is singular after one product of each category
+	utils.Expect(t, "0 3 5 1 2 ", ids(NewDPP(similarity, 0.5).Rerank(candidates(), 5)))
+	utils.Expect(t, "", ids(NewDPP(similarity, 0.5).Rerank(candidates(), -1)))
+}
+
+func TestCategoryCoverage(t *testing.T) {
+	r := NewCategoryCoverage(catalog(), 1)
+	utils.Expect(t, "0 3 5 1 ", ids(r.Rerank(candidates(), 4)))
+	r.Level, r.MaxPerCategory = 1, 2
+	utils.Expect(t, "0 1 3 4 ", ids(r.Rerank(candidates(), 4)))
+
+	// the best candidate of every category comes first
+	r.MaxPerCategory, r.CoverFirst = 0, true
+	utils.Expect(t, "0 3 1 ", ids(r.Rerank(candidates(), 3)))
+	utils.Expect(t, "", ids(r.Rerank(candidates(), -1)))
+}
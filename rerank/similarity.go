@@ -0,0 +1,102 @@
+// Copyright (c) 2014 Feng Wang <wffrank1987@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language
+
+package rerank
+
+import (
+	gomath "math"
+
+	"github.com/numb3r3/gorec/core"
+	"github.com/numb3r3/gorec/math"
+)
+
+// The similarity of two products in [0, 1]. The Similarity methods of the
+// item-kNN, content-based and random-walk models can be used directly.
+type ItemSimilarity func(a, b int) float64
+
+// The cosine similarity of the rows of the product factor matrix, clamped at 0
+func FactorSimilarity(factors *math.DenseMatrix) ItemSimilarity {
+	return func(a, b int) float64 {
+		if a < 0 || b < 0 || a >= factors.Rows() || b >= factors.Rows() {
+			return 0
+		}
+		x, y := factors.RowSlice(a), factors.RowSlice(b)
+		var xy, xx, yy float64
+		for i := range x {
+			xy += x[i] * y[i]
+			xx += x[i] * x[i]
+			yy += y[i] * y[i]
+		}
+		if xx == 0 || yy == 0 {
+			return 0
+		}
+		return gomath.Max(0, xy/gomath.Sqrt(xx*yy))
+	}
+}
+
+// The similarity of the categories of the products in the taxonomy, the
+// depth of their deepest common category over the depth of the deeper one
+// (both counted from 1 at the roots). The products of the same category
+// have similarity 1, and those of unrelated categories 0.
+func CategorySimilarity(catalog *core.Catalog) ItemSimilarity {
+	taxonomy := catalog.GetTaxonomy()
+	return func(a, b int) float64 {
+		pa, pb := catalog.GetProduct(a), catalog.GetProduct(b)
+		if pa == nil || pb == nil || pa.Category == "" || pb.Category == "" {
+			return 0
+		}
+		if pa.Category == pb.Category {
+			return 1
+		}
+		ca, cb := taxonomy.GetCategory(pa.Category), taxonomy.GetCategory(pb.Category)
+		if ca == nil || cb == nil {
+			return 0
+		}
+		deeper := gomath.Max(float64(ca.Depth()), float64(cb.Depth())) + 1
+		for c := ca; c != nil; c = c.Parent {
+			if taxonomy.IsUnder(cb.Name, c.Name) {
+				return float64(c.Depth()+1) / deeper
+			}
+		}
+		return 0
+	}
+}
+
+// The weighted average of the similarities
+func MixSimilarity(similarities []ItemSimilarity, weights []float64) ItemSimilarity {
+	return func(a, b int) float64 {
+		var s, total float64
+		for i, similarity := range similarities {
+			s += weights[i] * similarity(a, b)
+			total += weights[i]
+		}
+		if total == 0 {
+			return 0
+		}
+		return s / total
+	}
+}
+
+// Get the average pairwise dissimilarity of the products of the list
+func IntraListDiversity(products []*core.ScoredProduct, similarity ItemSimilarity) float64 {
+	if len(products) < 2 {
+		return 0
+	}
+	var sum float64
+	for i, a := range products {
+		for _, b := range products[i+1:] {
+			sum += 1 - similarity(a.ProductId, b.ProductId)
+		}
+	}
+	return sum / float64(len(products)*(len(products)-1)/2)
+}
@@ -0,0 +1,128 @@
+// Copyright (c) 2014 Feng Wang <wffrank1987@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language
+
+package rules
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+
+	"github.com/numb3r3/gorec/core"
+)
+
+// The config of a rule. Type is one of exclude_purchased, exclude_seen,
+// exclude_out_of_stock, restrict_category, boost, cap_per_attribute and
+// attribute_filter, the other fields are the parameters of the type.
+type RuleConfig struct {
+	Type string `json:"type"`
+
+	Attribute  string      `json:"attribute,omitempty"`
+	Value      interface{} `json:"value,omitempty"`
+	Tag        string      `json:"tag,omitempty"`
+	Categories []string    `json:"categories,omitempty"`
+	Multiplier *float64    `json:"multiplier,omitempty"`
+	Offset     float64     `json:"offset,omitempty"`
+	Max        int         `json:"max,omitempty"`
+	Exclude    bool        `json:"exclude,omitempty"`
+}
+
+// The config file of the pipeline, e.g.
+//
+//	{"rules": [
+//	    {"type": "exclude_purchased"},
+//	    {"type": "exclude_out_of_stock", "attribute": "stock"},
+//	    {"type": "restrict_category", "categories": ["phones"]},
+//	    {"type": "boost", "tag": "promoted", "multiplier": 1.5},
+//	    {"type": "cap_per_attribute", "attribute": "brand", "max": 2}
+//	]}
+type Config struct {
+	Rules []RuleConfig `json:"rules"`
+}
+
+// Create the rule of the config
+func (c *RuleConfig) Build() (Rule, error) {
+	switch c.Type {
+	case "exclude_purchased":
+		return &ExcludePurchased{}, nil
+	case "exclude_seen":
+		return &ExcludeSeen{}, nil
+	case "exclude_out_of_stock":
+		attribute := c.Attribute
+		if attribute == "" {
+			attribute = "stock"
+		}
+		return &ExcludeOutOfStock{Attribute: attribute}, nil
+	case "restrict_category":
+		if len(c.Categories) == 0 {
+			return nil, ErrorInvalidRule
+		}
+		return &RestrictCategory{Categories: c.Categories}, nil
+	case "boost":
+		if c.Tag == "" && c.Attribute == "" {
+			return nil, ErrorInvalidRule
+		}
+		multiplier := 1.0
+		if c.Multiplier != nil {
+			multiplier = *c.Multiplier
+		}
+		return &Boost{Tag: c.Tag, Attribute: c.Attribute, Value: c.Value, Multiplier: multiplier, Offset: c.Offset}, nil
+	case "cap_per_attribute":
+		if c.Attribute == "" || c.Max <= 0 {
+			return nil, ErrorInvalidRule
+		}
+		return &CapPerAttribute{Attribute: c.Attribute, Max: c.Max}, nil
+	case "attribute_filter":
+		if c.Attribute == "" {
+			return nil, ErrorInvalidRule
+		}
+		return &AttributeFilter{Attribute: c.Attribute, Value: c.Value, Exclude: c.Exclude}, nil
+	}
+	return nil, ErrorUnknownRule
+}
+
+// Read the config of the pipeline
+func ReadConfig(r io.Reader) (*Config, error) {
+	config := new(Config)
+	if err := json.NewDecoder(r).Decode(config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// Create the pipeline of the rules of the config
+func (c *Config) Build(catalog *core.Catalog, history *core.HistoryIndex) (*Pipeline, error) {
+	pipeline := NewPipeline(catalog, history)
+	for i := range c.Rules {
+		rule, err := c.Rules[i].Build()
+		if err != nil {
+			return nil, err
+		}
+		pipeline.Add(rule)
+	}
+	return pipeline, nil
+}
+
+// Create the pipeline of the config file
+func LoadPipeline(path string, catalog *core.Catalog, history *core.HistoryIndex) (*Pipeline, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	config, err := ReadConfig(f)
+	if err != nil {
+		return nil, err
+	}
+	return config.Build(catalog, history)
+}
@@ -0,0 +1,48 @@
+// Copyright (c) 2014 Feng Wang <wffrank1987@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language
+
+package rules
+
+import (
+	"fmt"
+)
+
+const (
+	// The rule type in the config is unknown
+	errorUnknownRule = iota
+	// A required parameter of the rule is missing or invalid
+	errorInvalidRule
+)
+
+type error_ int
+
+func (e error_) Error() string {
+	switch e {
+	case errorUnknownRule:
+		return "Unknown rule type"
+	case errorInvalidRule:
+		return "Invalid rule parameters"
+	}
+	return fmt.Sprintf("Unknown error code %d", e)
+}
+
+func (e error_) String() string {
+	return e.Error()
+}
+
+var (
+	// The rule type in the config is unknown
+	ErrorUnknownRule error_ = error_(errorUnknownRule)
+	// A required parameter of the rule is missing or invalid
+	ErrorInvalidRule error_ = error_(errorInvalidRule)
+)
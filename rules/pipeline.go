@@ -0,0 +1,106 @@
+// Copyright (c) 2014 Feng Wang <wffrank1987@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language
+
+package rules
+
+import (
+	"github.com/numb3r3/gorec/core"
+)
+
+// The rules applied in order to the recommendations
+type Pipeline struct {
+	catalog *core.Catalog
+
+	history *core.HistoryIndex
+
+	rules []Rule
+}
+
+// Create the pipeline evaluating the rules against the catalog and the
+// history, either of which can be nil
+func NewPipeline(catalog *core.Catalog, history *core.HistoryIndex, rules ...Rule) *Pipeline {
+	if history == nil {
+		history = core.NewHistoryIndex()
+	}
+	return &Pipeline{catalog, history, rules}
+}
+
+// Add the rule to the end of the pipeline
+func (p *Pipeline) Add(rule Rule) *Pipeline {
+	p.rules = append(p.rules, rule)
+	return p
+}
+
+// Get the rules of the pipeline
+func (p *Pipeline) Rules() []Rule {
+	return p.rules
+}
+
+// Set the history index the rules are evaluated against
+func (p *Pipeline) SetHistory(history *core.HistoryIndex) {
+	p.history = history
+}
+
+// Apply the rules to the products recommended to the user, and get the
+// products sorted by descending score
+func (p *Pipeline) Apply(userId int, context *core.Context, products []*core.ScoredProduct) []*core.ScoredProduct {
+	request := &Request{
+		UserId:  userId,
+		History: p.history.GetHistory(userId),
+		Context: context,
+		Catalog: p.catalog,
+	}
+	result := make([]*core.ScoredProduct, len(products))
+	copy(result, products)
+	core.SortScoredProducts(result)
+	for _, rule := range p.rules {
+		result = rule.Apply(request, result)
+	}
+	return result
+}
+
+// A recommender applying the rule pipeline to the candidates of another
+// recommender
+type RuleRecommender struct {
+	recommender core.Recommender
+
+	pipeline *Pipeline
+
+	// The number of candidates requested from the recommender
+	depth int
+}
+
+func NewRuleRecommender(recommender core.Recommender, pipeline *Pipeline, depth int) *RuleRecommender {
+	return &RuleRecommender{recommender, pipeline, depth}
+}
+
+// Fit the recommender, and index the history of the records for the rules
+func (r *RuleRecommender) Fit(records []*core.Record) error {
+	if err := r.recommender.Fit(records); err != nil {
+		return err
+	}
+	r.pipeline.SetHistory(core.BuildHistoryIndex(records))
+	return nil
+}
+
+func (r *RuleRecommender) Recommend(userId int, context *core.Context, n int) []*core.ScoredProduct {
+	depth := r.depth
+	if depth < n {
+		depth = n
+	}
+	products := r.pipeline.Apply(userId, context, r.recommender.Recommend(userId, context, depth))
+	if len(products) > n {
+		products = products[:n]
+	}
+	return products
+}
@@ -0,0 +1,240 @@
+// Copyright (c) 2014 Feng Wang <wffrank1987@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language
+
+package rules
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/numb3r3/gorec/core"
+)
+
+// The request the rules are evaluated for
+type Request struct {
+	UserId int
+
+	// The history of the user, nil for the users without records
+	History *core.UserHistory
+
+	Context *core.Context
+
+	Catalog *core.Catalog
+}
+
+// Get the product from the catalog, nil if it is unknown
+func (r *Request) Product(id int) *core.Product {
+	if r.Catalog == nil {
+		return nil
+	}
+	return r.Catalog.GetProduct(id)
+}
+
+// A business rule filtering or re-scoring the recommended products. The
+// products are sorted by descending score before and after every rule.
+type Rule interface {
+	Apply(request *Request, products []*core.ScoredProduct) []*core.ScoredProduct
+}
+
+// Keep the products satisfying the predicate
+func filter(products []*core.ScoredProduct, keep func(p *core.ScoredProduct) bool) []*core.ScoredProduct {
+	kept := make([]*core.ScoredProduct, 0, len(products))
+	for _, p := range products {
+		if keep(p) {
+			kept = append(kept, p)
+		}
+	}
+	return kept
+}
+
+// Exclude the products the user has purchased
+type ExcludePurchased struct{}
+
+func (rule *ExcludePurchased) Apply(request *Request, products []*core.ScoredProduct) []*core.ScoredProduct {
+	if request.History == nil {
+		return products
+	}
+	return filter(products, func(p *core.ScoredProduct) bool {
+		_, ok := request.History.Purchased[p.ProductId]
+		return !ok
+	})
+}
+
+// Exclude the products the user has interacted with in any way
+type ExcludeSeen struct{}
+
+func (rule *ExcludeSeen) Apply(request *Request, products []*core.ScoredProduct) []*core.ScoredProduct {
+	if request.History == nil {
+		return products
+	}
+	return filter(products, func(p *core.ScoredProduct) bool {
+		_, ok := request.History.Seen[p.ProductId]
+		return !ok
+	})
+}
+
+// Exclude the products whose stock attribute is false or not positive. The
+// products without the attribute are kept, and the unknown products are
+// excluded. All of the products are kept if the request has no catalog.
+type ExcludeOutOfStock struct {
+	Attribute string
+}
+
+func (rule *ExcludeOutOfStock) Apply(request *Request, products []*core.ScoredProduct) []*core.ScoredProduct {
+	if request.Catalog == nil {
+		return products
+	}
+	return filter(products, func(p *core.ScoredProduct) bool {
+		product := request.Product(p.ProductId)
+		if product == nil {
+			return false
+		}
+		if inStock, ok := product.GetBoolAttribute(rule.Attribute); ok {
+			return inStock
+		}
+		if stock, ok := product.GetFloatAttribute(rule.Attribute); ok {
+			return stock > 0
+		}
+		return true
+	})
+}
+
+// Keep the products in the subtree of any of the categories. Like
+// ExcludeOutOfStock, all of the products are kept if the request has no
+// catalog, since their categories are unknown.
+type RestrictCategory struct {
+	Categories []string
+}
+
+func (rule *RestrictCategory) Apply(request *Request, products []*core.ScoredProduct) []*core.ScoredProduct {
+	if request.Catalog == nil {
+		return products
+	}
+	return filter(products, func(p *core.ScoredProduct) bool {
+		for _, category := range rule.Categories {
+			if request.Catalog.InCategory(p.ProductId, category) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// Boost the promoted products, which have the tag or the attribute with
+// the value. The score of a promoted product becomes
+// score * Multiplier + Offset.
+type Boost struct {
+	Tag string
+
+	Attribute string
+	Value     interface{}
+
+	Multiplier float64
+	Offset     float64
+}
+
+func (rule *Boost) promoted(product *core.Product) bool {
+	if product == nil {
+		return false
+	}
+	if rule.Tag != "" && product.HasTag(rule.Tag) {
+		return true
+	}
+	if rule.Attribute == "" {
+		return false
+	}
+	return equal(product, rule.Attribute, rule.Value)
+}
+
+func (rule *Boost) Apply(request *Request, products []*core.ScoredProduct) []*core.ScoredProduct {
+	boosted := make([]*core.ScoredProduct, len(products))
+	for i, p := range products {
+		boosted[i] = p
+		if rule.promoted(request.Product(p.ProductId)) {
+			boosted[i] = &core.ScoredProduct{ProductId: p.ProductId, Score: p.Score*rule.Multiplier + rule.Offset}
+		}
+	}
+	core.SortScoredProducts(boosted)
+	return boosted
+}
+
+// Keep at most Max products of each value of the attribute (e.g. brand).
+// The products without the attribute are not capped. The values are
+// grouped by their printed form, so any value type can be capped.
+type CapPerAttribute struct {
+	Attribute string
+
+	Max int
+}
+
+func (rule *CapPerAttribute) Apply(request *Request, products []*core.ScoredProduct) []*core.ScoredProduct {
+	counts := make(map[string]int)
+	return filter(products, func(p *core.ScoredProduct) bool {
+		product := request.Product(p.ProductId)
+		if product == nil {
+			return true
+		}
+		value := product.GetAttribute(rule.Attribute)
+		if value == nil {
+			return true
+		}
+		key := fmt.Sprint(value)
+		if counts[key] >= rule.Max {
+			return false
+		}
+		counts[key]++
+		return true
+	})
+}
+
+// Keep (or exclude if Exclude is true) the products whose attribute equals
+// the value
+type AttributeFilter struct {
+	Attribute string
+	Value     interface{}
+
+	Exclude bool
+}
+
+func (rule *AttributeFilter) Apply(request *Request, products []*core.ScoredProduct) []*core.ScoredProduct {
+	return filter(products, func(p *core.ScoredProduct) bool {
+		return equal(request.Product(p.ProductId), rule.Attribute, rule.Value) != rule.Exclude
+	})
+}
+
+// Whether the attribute of the product equals the value, the numbers are
+// compared as float64 and the other values deeply
+func equal(product *core.Product, attribute string, value interface{}) bool {
+	if product == nil {
+		return false
+	}
+	if f, ok := toFloat(value); ok {
+		v, ok := product.GetFloatAttribute(attribute)
+		return ok && v == f
+	}
+	return reflect.DeepEqual(product.GetAttribute(attribute), value)
+}
+
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	}
+	return 0, false
+}
@@ -0,0 +1,180 @@
+// Copyright (c) 2014 Feng Wang <wffrank1987@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language
+
+package rules
+
+import (
+	"fmt"
+	"github.com/numb3r3/gorec/core"
+	"github.com/numb3r3/gorec/utils"
+	"strings"
+	"testing"
+	"time"
+)
+
+func catalog() *core.Catalog {
+	taxonomy := core.NewTaxonomy()
+	taxonomy.AddCategory("phones", "")
+	taxonomy.AddCategory("laptops", "")
+	c := core.NewCatalog(taxonomy)
+	brands := []string{"acme", "acme", "acme", "globex", "globex", "initech"}
+	for id, brand := range brands {
+		p := core.NewProduct(id, fmt.Sprint("product", id))
+		p.Category = "phones"
+		if id == 5 {
+			p.Category = "laptops"
+		}
+		p.SetAttribute("brand", brand)
+		p.SetAttribute("stock", 10)
+		c.AddProduct(p)
+	}
+	c.GetProduct(4).SetAttribute("stock", 0)
+	c.GetProduct(3).Tags = []string{"promoted"}
+	return c
+}
+
+func candidates() []*core.ScoredProduct {
+	products := make([]*core.ScoredProduct, 6)
+	for i := range products {
+		products[i] = &core.ScoredProduct{ProductId: i, Score: float64(6 - i)}
+	}
+	return products
+}
+
+func ids(products []*core.ScoredProduct) string {
+	s := ""
+	for _, p := range products {
+		s += fmt.Sprint(p.ProductId, " ")
+	}
+	return s
+}
+
+func TestPipeline(t *testing.T) {
+	history := core.BuildHistoryIndex([]*core.Record{
+		core.NewEvent(0, 1, core.EventPurchase, time.Now()),
+	})
+	config := `{"rules": [
+		{"type": "exclude_purchased"},
+		{"type": "exclude_out_of_stock", "attribute": "stock"},
+		{"type": "boost", "tag": "promoted", "multiplier": 2},
+		{"type": "cap_per_attribute", "attribute": "brand", "max": 1}
+	]}`
+	c, err := ReadConfig(strings.NewReader(config))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pipeline, err := c.Build(catalog(), history)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 1 is purchased, 4 is out of stock, 3 is boosted to 6, 2 is capped
+	utils.Expect(t, "0 3 5 ", ids(pipeline.Apply(0, nil, candidates())))
+
+	pipeline.Add(&RestrictCategory{Categories: []string{"laptops"}})
+	utils.Expect(t, "5 ", ids(pipeline.Apply(0, nil, candidates())))
+}
+
+func TestRules(t *testing.T) {
+	// nothing is known to be out of stock without a catalog
+	rule := &ExcludeOutOfStock{Attribute: "stock"}
+	utils.Expect(t, "0 1 2 3 4 5 ", ids(rule.Apply(&Request{}, candidates())))
+	utils.Expect(t, "0 1 2 3 5 ", ids(rule.Apply(&Request{Catalog: catalog()}, candidates())))
+	restrict := &RestrictCategory{Categories: []string{"laptops"}}
+	utils.Expect(t, "0 1 2 3 4 5 ", ids(restrict.Apply(&Request{}, candidates())))
+	utils.Expect(t, "5 ", ids(restrict.Apply(&Request{Catalog: catalog()}, candidates())))
+
+	// 1 is purchased and 2 is viewed
+	history := core.BuildHistoryIndex([]*core.Record{
+		core.NewEvent(0, 1, core.EventPurchase, time.Now()),
+		core.NewEvent(0, 2, core.EventView, time.Now()),
+	})
+	seen := &ExcludeSeen{}
+	utils.Expect(t, "0 1 2 3 4 5 ", ids(seen.Apply(&Request{}, candidates())))
+	utils.Expect(t, "0 3 4 5 ", ids(seen.Apply(&Request{History: history.GetHistory(0)}, candidates())))
+
+	// the globex products 3 and 4 get 10 more, and the number 0 matches
+	// the stock of 4 whatever its type
+	request := &Request{Catalog: catalog()}
+	boost := &Boost{Attribute: "brand", Value: "globex", Multiplier: 1, Offset: 10}
+	utils.Expect(t, "3 4 0 1 2 5 ", ids(boost.Apply(request, candidates())))
+	boost = &Boost{Attribute: "stock", Value: 0.0, Multiplier: 1, Offset: 10}
+	utils.Expect(t, "4 0 1 2 3 5 ", ids(boost.Apply(request, candidates())))
+
+	// the slices are neither hashable nor comparable
+	c := catalog()
+	for id := 0; id < 6; id++ {
+		c.GetProduct(id).SetAttribute("colors", []string{"red", fmt.Sprint(id % 2)})
+	}
+	request = &Request{Catalog: c}
+	capped := &CapPerAttribute{Attribute: "colors", Max: 2}
+	utils.Expect(t, "0 1 2 3 ", ids(capped.Apply(request, candidates())))
+	filter := &AttributeFilter{Attribute: "colors", Value: []string{"red", "1"}}
+	utils.Expect(t, "1 3 5 ", ids(filter.Apply(request, candidates())))
+	filter.Exclude = true
+	utils.Expect(t, "0 2 4 ", ids(filter.Apply(request, candidates())))
+}
+
+// The recommender returning the first n candidates
+type fixedRecommender struct {
+	err error
+
+	// The n of the latest request
+	n int
+}
+
+func (r *fixedRecommender) Fit(records []*core.Record) error {
+	return r.err
+}
+
+func (r *fixedRecommender) Recommend(userId int, context *core.Context, n int) []*core.ScoredProduct {
+	r.n = n
+	products := candidates()
+	if n < len(products) {
+		products = products[:n]
+	}
+	return products
+}
+
+func TestRuleRecommender(t *testing.T) {
+	fixed := new(fixedRecommender)
+	m := NewRuleRecommender(fixed, NewPipeline(catalog(), nil, &ExcludePurchased{}), 4)
+	err := m.Fit([]*core.Record{
+		core.NewEvent(0, 0, core.EventPurchase, time.Now()),
+		core.NewEvent(0, 2, core.EventPurchase, time.Now()),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the depth candidates are filtered, then truncated to n
+	utils.Expect(t, "1 3 ", ids(m.Recommend(0, nil, 2)))
+	utils.Expect(t, "4", fixed.n)
+	utils.Expect(t, "1 3 4 5 ", ids(m.Recommend(0, nil, 6)))
+	utils.Expect(t, "6", fixed.n)
+	utils.Expect(t, "0 ", ids(m.Recommend(1, nil, 1)))
+
+	// the history is replaced by the records of the new fit
+	m.Fit([]*core.Record{core.NewEvent(0, 1, core.EventPurchase, time.Now())})
+	utils.Expect(t, "0 2 ", ids(m.Recommend(0, nil, 2)))
+
+	// and kept when the recommender fails
+	fixed.err = core.ErrorEmptyRecords
+	utils.Expect(t, core.ErrorEmptyRecords.Error(), m.Fit(nil))
+	utils.Expect(t, "0 2 ", ids(m.Recommend(0, nil, 2)))
+}
+
+func TestConfigErrors(t *testing.T) {
+	_, err := (&RuleConfig{Type: "unknown"}).Build()
+	utils.Expect(t, ErrorUnknownRule.Error(), fmt.Sprint(err))
+	_, err = (&RuleConfig{Type: "cap_per_attribute"}).Build()
+	utils.Expect(t, ErrorInvalidRule.Error(), fmt.Sprint(err))
+}
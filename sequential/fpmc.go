@@ -0,0 +1,213 @@
+// Copyright (c) 2014 Feng Wang <wffrank1987@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language
+
+package sequential
+
+import (
+	gomath "math"
+	"math/rand"
+
+	"github.com/numb3r3/gorec/core"
+	"github.com/numb3r3/gorec/math"
+)
+
+// The options of the factorized personalized Markov chains
+type FPMCOptions struct {
+
+	// The number of latent factors of each factorization
+	NumFactors int
+
+	// The number of passes, each pass draws as many samples as transitions
+	NumEpochs int
+
+	LearningRate float64
+
+	// The L2 regularization of the factors
+	Regularization float64
+
+	// The standard deviation of the initial factors
+	InitStd float64
+
+	// The seed of the random number generator
+	Seed int64
+}
+
+func DefaultFPMCOptions() FPMCOptions {
+	return FPMCOptions{
+		NumFactors:     32,
+		NumEpochs:      30,
+		LearningRate:   0.05,
+		Regularization: 0.01,
+		InitStd:        0.1,
+		Seed:           1,
+	}
+}
+
+// The factorized personalized Markov chains (Rendle et al., 2010) with
+// the previous product as the basket. The score of product i for user u
+// after product l combines the long-term taste of the user and the
+// factorized transition from l:
+//
+//	x(u, l, i) = <VUI_u, VIU_i> + <VIL_i, VLI_l>
+//
+// and it is trained by S-BPR against randomly drawn products.
+type FPMC struct {
+	options FPMCOptions
+
+	// The user-item factorization: users x factors and items x factors
+	VUI, VIU *math.DenseMatrix
+
+	// The item-item transition factorization: items x factors for the
+	// next item and the previous item
+	VIL, VLI *math.DenseMatrix
+
+	// user -> the last product of the user
+	last map[int]int
+
+	// user -> the products of the user
+	seen map[int]map[int]bool
+}
+
+// The maximum number of draws to find a negative product for a user
+const maxNegativeTries = 100
+
+func NewFPMC(options FPMCOptions) *FPMC {
+	m := new(FPMC)
+	m.options = options
+	return m
+}
+
+// Fit on the time-ordered sequences of the records
+func (m *FPMC) Fit(records []*core.Record) error {
+	if len(records) == 0 {
+		return core.ErrorEmptyRecords
+	}
+	rows, cols := core.RecordsDimension(records)
+	return m.TrainSequences(SequencesFromRecords(records), rows, cols)
+}
+
+// Train on the product sequence of each user with the number of the users
+// and products, ErrorIllegalId is returned for an id out of the ranges
+func (m *FPMC) TrainSequences(sequences map[int][]int, numUsers, numItems int) error {
+	for u, seq := range sequences {
+		if u < 0 || u >= numUsers {
+			return core.ErrorIllegalId
+		}
+		for _, item := range seq {
+			if item < 0 || item >= numItems {
+				return core.ErrorIllegalId
+			}
+		}
+	}
+	samples := transitions(sequences)
+	if len(samples) == 0 || numItems < 2 {
+		return core.ErrorEmptyRecords
+	}
+	o := m.options
+	rng := rand.New(rand.NewSource(o.Seed))
+	m.VUI = math.RandomNormals(numUsers, o.NumFactors, o.InitStd, rng)
+	m.VIU = math.RandomNormals(numItems, o.NumFactors, o.InitStd, rng)
+	m.VIL = math.RandomNormals(numItems, o.NumFactors, o.InitStd, rng)
+	m.VLI = math.RandomNormals(numItems, o.NumFactors, o.InitStd, rng)
+	m.last = lastItems(sequences)
+	m.seen = make(map[int]map[int]bool, len(sequences))
+	for u, seq := range sequences {
+		m.seen[u] = make(map[int]bool, len(seq))
+		for _, item := range seq {
+			m.seen[u][item] = true
+		}
+	}
+
+	for epoch := 0; epoch < o.NumEpochs; epoch++ {
+		for n := 0; n < len(samples); n++ {
+			s := samples[rng.Intn(len(samples))]
+			j, ok := m.sampleNegative(s.user, numItems, rng)
+			if !ok {
+				continue
+			}
+			m.update(s.user, s.from, s.to, j)
+		}
+	}
+	return nil
+}
+
+// Draw a product the user has not interacted with, false if none is
+// found in maxNegativeTries draws
+func (m *FPMC) sampleNegative(u, numItems int, rng *rand.Rand) (int, bool) {
+	for t := 0; t < maxNegativeTries; t++ {
+		j := rng.Intn(numItems)
+		if !m.seen[u][j] {
+			return j, true
+		}
+	}
+	return 0, false
+}
+
+// One S-BPR step ranking i over j for user u after product l
+func (m *FPMC) update(u, l, i, j int) {
+	o := m.options
+	vu, vl := m.VUI.RowSlice(u), m.VLI.RowSlice(l)
+	ui, uj := m.VIU.RowSlice(i), m.VIU.RowSlice(j)
+	li, lj := m.VIL.RowSlice(i), m.VIL.RowSlice(j)
+
+	x := math.DotSlices(vu, ui) - math.DotSlices(vu, uj) + math.DotSlices(li, vl) - math.DotSlices(lj, vl)
+	g := o.LearningRate / (1 + gomath.Exp(x))
+	reg := o.LearningRate * o.Regularization
+	for f := range vu {
+		u_, ui_, uj_ := vu[f], ui[f], uj[f]
+		l_, li_, lj_ := vl[f], li[f], lj[f]
+		vu[f] += g*(ui_-uj_) - reg*u_
+		ui[f] += g*u_ - reg*ui_
+		uj[f] += -g*u_ - reg*uj_
+		vl[f] += g*(li_-lj_) - reg*l_
+		li[f] += g*l_ - reg*li_
+		lj[f] += -g*l_ - reg*lj_
+	}
+}
+
+// Get the score of the product for the user after the previous product
+func (m *FPMC) Score(userId, previous, item int) float64 {
+	if m.VIU == nil || item < 0 || item >= m.VIU.Rows() {
+		return 0
+	}
+	var s float64
+	if userId >= 0 && userId < m.VUI.Rows() {
+		s += math.DotSlices(m.VUI.RowSlice(userId), m.VIU.RowSlice(item))
+	}
+	if previous >= 0 && previous < m.VLI.Rows() {
+		s += math.DotSlices(m.VIL.RowSlice(item), m.VLI.RowSlice(previous))
+	}
+	return s
+}
+
+// Get the n best products for the user after the previous product,
+// excluding the products the user has interacted with
+func (m *FPMC) NextItems(userId, previous, n int) []*core.ScoredProduct {
+	if m.VIU == nil {
+		return nil
+	}
+	scores := make([]float64, m.VIU.Rows())
+	for i := range scores {
+		scores[i] = m.Score(userId, previous, i)
+	}
+	return core.TopNSlice(scores, n, m.seen[userId])
+}
+
+// Recommend the products following the last product of the user
+func (m *FPMC) Recommend(userId int, context *core.Context, n int) []*core.ScoredProduct {
+	previous, ok := m.last[userId]
+	if !ok {
+		return nil
+	}
+	return m.NextItems(userId, previous, n)
+}
@@ -0,0 +1,88 @@
+// Copyright (c) 2014 Feng Wang <wffrank1987@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language
+
+package sequential
+
+import (
+	"github.com/numb3r3/gorec/core"
+)
+
+// The first-order Markov chain next-item recommender. The probability of
+// moving from product i to product j is the fraction of the transitions
+// out of i going to j, over all of the users.
+type MarkovChain struct {
+	// from -> to -> number of transitions
+	counts map[int]map[int]float64
+
+	// from -> number of transitions out of it
+	totals map[int]float64
+
+	// user -> the last product of the user
+	last map[int]int
+}
+
+func NewMarkovChain() *MarkovChain {
+	return new(MarkovChain)
+}
+
+// Fit on the time-ordered sequences of the records
+func (m *MarkovChain) Fit(records []*core.Record) error {
+	if len(records) == 0 {
+		return core.ErrorEmptyRecords
+	}
+	m.TrainSequences(SequencesFromRecords(records))
+	return nil
+}
+
+// Train on the product sequence of each user
+func (m *MarkovChain) TrainSequences(sequences map[int][]int) {
+	m.counts = make(map[int]map[int]float64)
+	m.totals = make(map[int]float64)
+	for _, t := range transitions(sequences) {
+		if m.counts[t.from] == nil {
+			m.counts[t.from] = make(map[int]float64)
+		}
+		m.counts[t.from][t.to]++
+		m.totals[t.from]++
+	}
+	m.last = lastItems(sequences)
+}
+
+// Get the probability of moving from one product to another
+func (m *MarkovChain) TransitionProbability(from, to int) float64 {
+	total := m.totals[from]
+	if total == 0 {
+		return 0
+	}
+	return m.counts[from][to] / total
+}
+
+// Get the n most probable products following the current one
+func (m *MarkovChain) NextItems(current, n int) []*core.ScoredProduct {
+	scores := make(map[int]float64)
+	for to := range m.counts[current] {
+		if to != current {
+			scores[to] = m.TransitionProbability(current, to)
+		}
+	}
+	return core.TopN(scores, n)
+}
+
+// Recommend the products following the last product of the user
+func (m *MarkovChain) Recommend(userId int, context *core.Context, n int) []*core.ScoredProduct {
+	current, ok := m.last[userId]
+	if !ok {
+		return nil
+	}
+	return m.NextItems(current, n)
+}
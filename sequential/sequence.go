@@ -0,0 +1,74 @@
+// Copyright (c) 2014 Feng Wang <wffrank1987@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language
+
+package sequential
+
+import (
+	"sort"
+
+	"github.com/numb3r3/gorec/core"
+)
+
+// Get the sequence of the products of each user in time order. The
+// repeated interactions with the same product in a row are collapsed.
+func SequencesFromRecords(records []*core.Record) map[int][]int {
+	byUser := make(map[int][]*core.Record)
+	for _, r := range records {
+		byUser[r.UserId] = append(byUser[r.UserId], r)
+	}
+	sequences := make(map[int][]int, len(byUser))
+	for u, history := range byUser {
+		core.SortRecordsByTime(history)
+		var seq []int
+		for _, r := range history {
+			if len(seq) == 0 || seq[len(seq)-1] != r.ProductId {
+				seq = append(seq, r.ProductId)
+			}
+		}
+		sequences[u] = seq
+	}
+	return sequences
+}
+
+// A transition of a user from a product to the next one
+type transition struct {
+	user, from, to int
+}
+
+// Get all of the transitions of the sequences, ordered by user
+func transitions(sequences map[int][]int) []transition {
+	users := make([]int, 0, len(sequences))
+	for u := range sequences {
+		users = append(users, u)
+	}
+	sort.Ints(users)
+	var result []transition
+	for _, u := range users {
+		seq := sequences[u]
+		for t := 1; t < len(seq); t++ {
+			result = append(result, transition{u, seq[t-1], seq[t]})
+		}
+	}
+	return result
+}
+
+// Get the last product of each sequence
+func lastItems(sequences map[int][]int) map[int]int {
+	last := make(map[int]int, len(sequences))
+	for u, seq := range sequences {
+		if len(seq) > 0 {
+			last[u] = seq[len(seq)-1]
+		}
+	}
+	return last
+}
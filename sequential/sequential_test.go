@@ -0,0 +1,86 @@
+// Copyright (c) 2014 Feng Wang <wffrank1987@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language
+
+package sequential
+
+import (
+	"fmt"
+	"github.com/numb3r3/gorec/core"
+	"github.com/numb3r3/gorec/utils"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestSequencesFromRecords(t *testing.T) {
+	t0 := time.Date(2014, 1, 1, 0, 0, 0, 0, time.UTC)
+	records := []*core.Record{
+		core.NewEvent(0, 2, core.EventView, t0.Add(2*time.Hour)),
+		core.NewEvent(0, 1, core.EventView, t0),
+		core.NewEvent(0, 1, core.EventClick, t0.Add(time.Hour)),
+		core.NewEvent(1, 3, core.EventView, t0),
+	}
+	sequences := SequencesFromRecords(records)
+	utils.Expect(t, "[1 2]", sequences[0])
+	utils.Expect(t, "[3]", sequences[1])
+}
+
+// Each user walks 3 steps on the cycle 0 -> 1 -> ... -> 5 -> 0 from its own id
+func cycleSequences() map[int][]int {
+	sequences := make(map[int][]int)
+	for u := 0; u < 6; u++ {
+		sequences[u] = []int{u, (u + 1) % 6, (u + 2) % 6}
+	}
+	return sequences
+}
+
+func TestMarkovChain(t *testing.T) {
+	model := NewMarkovChain()
+	model.TrainSequences(map[int][]int{0: {1, 2, 1, 3}, 1: {1, 2}})
+	utils.ExpectNear(t, 2.0/3, model.TransitionProbability(1, 2), 1e-12)
+	utils.ExpectNear(t, 1.0/3, model.TransitionProbability(1, 3), 1e-12)
+	utils.ExpectNear(t, 0, model.TransitionProbability(3, 1), 1e-12)
+
+	result := model.Recommend(1, nil, 2)
+	utils.Expect(t, "1", len(result))
+	utils.Expect(t, "1", result[0].ProductId)
+	utils.Expect(t, "0", len(model.Recommend(2, nil, 2)))
+}
+
+func TestFPMC(t *testing.T) {
+	model := NewFPMC(DefaultFPMCOptions())
+	if err := model.TrainSequences(cycleSequences(), 6, 6); err != nil {
+		t.Fatal(err)
+	}
+	for u := 0; u < 6; u++ {
+		result := model.Recommend(u, nil, 1)
+		utils.Expect(t, fmt.Sprint((u+3)%6), result[0].ProductId)
+	}
+
+	utils.Expect(t, core.ErrorIllegalId.Error(), model.TrainSequences(cycleSequences(), 5, 6))
+	utils.Expect(t, core.ErrorIllegalId.Error(), model.TrainSequences(cycleSequences(), 6, 5))
+	utils.Expect(t, core.ErrorIllegalId.Error(), model.TrainSequences(map[int][]int{0: {1, -1}}, 6, 6))
+}
+
+func TestFPMCNegatives(t *testing.T) {
+	model := NewFPMC(DefaultFPMCOptions())
+	model.seen = map[int]map[int]bool{0: {0: true, 1: true, 2: true}, 1: {0: true, 1: true}}
+	rng := rand.New(rand.NewSource(1))
+	// the negatives are never in the history of the user
+	for i := 0; i < 100; i++ {
+		j, ok := model.sampleNegative(0, 4, rng)
+		utils.Expect(t, "true 3", fmt.Sprint(ok, " ", j))
+	}
+	_, ok := model.sampleNegative(1, 2, rng)
+	utils.Expect(t, "false", ok)
+}
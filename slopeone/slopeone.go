@@ -0,0 +1,227 @@
+// Copyright (c) 2014 Feng Wang <wffrank1987@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language
+
+package slopeone
+
+import (
+	"sync"
+
+	"github.com/numb3r3/gorec/core"
+	"github.com/numb3r3/gorec/math"
+)
+
+// The accumulated difference of the ratings on two items
+type deviation struct {
+	// The sum of r_ui - r_uj over the users who rated both
+	sum float64
+
+	// The number of the users who rated both
+	count int
+}
+
+// The Slope One rating predictor (Lemire and Maclachlan, 2005). The
+// prediction of user u on item i is the average of r_uj + dev_ij over
+// the items j rated by u, where dev_ij is the average difference of the
+// ratings on i and j. The weighted Slope One weights each item j by the
+// number of users who rated both.
+//
+// The deviation table is updated in place when a rating is added or
+// removed, so the model never needs to be retrained. It is safe for
+// concurrent use.
+//
+// A rating of 0 means no rating, as a zero element of SparseMatrix does,
+// so it is dropped by Fit and Train and removes the rating in AddRating.
+type SlopeOne struct {
+	mu sync.RWMutex
+
+	weighted bool
+
+	// item i -> item j -> deviation of i from j
+	deviations map[int]map[int]*deviation
+
+	// user -> item -> rating
+	ratings map[int]map[int]float64
+}
+
+// Create a Slope One predictor, the weighted variant if weighted is true
+func NewSlopeOne(weighted bool) *SlopeOne {
+	model := new(SlopeOne)
+	model.weighted = weighted
+	model.reset()
+	return model
+}
+
+func (m *SlopeOne) reset() {
+	m.deviations = make(map[int]map[int]*deviation)
+	m.ratings = make(map[int]map[int]float64)
+}
+
+// Fit on the explicit ratings of the records, replacing the current model.
+// The latest rating wins if a user rated an item several times.
+func (m *SlopeOne) Fit(records []*core.Record) error {
+	if len(records) == 0 {
+		return core.ErrorEmptyRecords
+	}
+	R, err := core.RatingMatrix(records, 0, 0)
+	if err != nil {
+		return err
+	}
+	return m.Train(R)
+}
+
+// Train on the user x item rating matrix, replacing the current model
+func (m *SlopeOne) Train(R *math.SparseMatrix) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.reset()
+	R.Each(func(u, i int, v float64) {
+		if m.ratings[u] == nil {
+			m.ratings[u] = make(map[int]float64)
+		}
+		m.ratings[u][i] = v
+	})
+	for _, items := range m.ratings {
+		for i, ri := range items {
+			for j, rj := range items {
+				if i != j {
+					m.accumulate(i, j, ri-rj, 1)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Add or replace the rating of the user on the item, a rating of 0
+// removes it
+func (m *SlopeOne) AddRating(userId, item int, rating float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.remove(userId, item)
+	if rating == 0 {
+		return
+	}
+	items := m.ratings[userId]
+	if items == nil {
+		items = make(map[int]float64)
+		m.ratings[userId] = items
+	}
+	for j, rj := range items {
+		m.accumulate(item, j, rating-rj, 1)
+		m.accumulate(j, item, rj-rating, 1)
+	}
+	items[item] = rating
+}
+
+// Remove the rating of the user on the item
+func (m *SlopeOne) RemoveRating(userId, item int) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.remove(userId, item)
+}
+
+func (m *SlopeOne) remove(userId, item int) bool {
+	items := m.ratings[userId]
+	rating, ok := items[item]
+	if !ok {
+		return false
+	}
+	delete(items, item)
+	for j, rj := range items {
+		m.accumulate(item, j, rj-rating, -1)
+		m.accumulate(j, item, rating-rj, -1)
+	}
+	return true
+}
+
+func (m *SlopeOne) accumulate(i, j int, diff float64, count int) {
+	row := m.deviations[i]
+	if row == nil {
+		row = make(map[int]*deviation)
+		m.deviations[i] = row
+	}
+	d := row[j]
+	if d == nil {
+		d = new(deviation)
+		row[j] = d
+	}
+	d.sum += diff
+	d.count += count
+	if d.count == 0 {
+		delete(row, j)
+	}
+}
+
+// Get the average deviation of item i from item j, and the number of
+// the users who rated both
+func (m *SlopeOne) Deviation(i, j int) (float64, int) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	d := m.deviations[i][j]
+	if d == nil {
+		return 0, 0
+	}
+	return d.sum / float64(d.count), d.count
+}
+
+// Predict the rating of the user on the item, 0 if no item rated by the
+// user has been co-rated with it
+func (m *SlopeOne) Predict(userId, item int) float64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	score, _ := m.predict(userId, item)
+	return score
+}
+
+func (m *SlopeOne) predict(userId, item int) (float64, bool) {
+	row := m.deviations[item]
+	var sum, weights float64
+	for j, rj := range m.ratings[userId] {
+		d := row[j]
+		if d == nil || j == item {
+			continue
+		}
+		w := 1.0
+		if m.weighted {
+			w = float64(d.count)
+		}
+		sum += w * (rj + d.sum/float64(d.count))
+		weights += w
+	}
+	if weights == 0 {
+		return 0, false
+	}
+	return sum / weights, true
+}
+
+// Recommend the items with the highest predicted ratings which the user has not rated
+func (m *SlopeOne) Recommend(userId int, context *core.Context, n int) []*core.ScoredProduct {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	rated := m.ratings[userId]
+	scores := make(map[int]float64)
+	for j := range rated {
+		for i := range m.deviations[j] {
+			if _, ok := rated[i]; ok {
+				continue
+			}
+			if _, ok := scores[i]; ok {
+				continue
+			}
+			if score, ok := m.predict(userId, i); ok {
+				scores[i] = score
+			}
+		}
+	}
+	return core.TopN(scores, n)
+}
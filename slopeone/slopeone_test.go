@@ -0,0 +1,94 @@
+// Copyright (c) 2014 Feng Wang <wffrank1987@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language
+
+package slopeone
+
+import (
+	"fmt"
+	"github.com/numb3r3/gorec/core"
+	"github.com/numb3r3/gorec/utils"
+	"testing"
+	"time"
+)
+
+// The example of Lemire and Maclachlan
+func ratings() []*core.Record {
+	now := time.Now()
+	return []*core.Record{
+		core.NewRating(0, 0, 5, now),
+		core.NewRating(0, 1, 3, now),
+		core.NewRating(0, 2, 2, now),
+		core.NewRating(1, 0, 3, now),
+		core.NewRating(1, 1, 4, now),
+		core.NewRating(2, 1, 2, now),
+		core.NewRating(2, 2, 5, now),
+	}
+}
+
+// Get all of the deviations between the items as a string
+func deviations(m *SlopeOne) string {
+	s := ""
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			dev, count := m.Deviation(i, j)
+			s += fmt.Sprintf("%.4f/%d ", dev, count)
+		}
+	}
+	return s
+}
+
+func TestSlopeOne(t *testing.T) {
+	m := NewSlopeOne(false)
+	if err := m.Fit(ratings()); err != nil {
+		t.Fatal(err)
+	}
+	dev, count := m.Deviation(0, 1)
+	utils.Expect(t, "0.5 2", fmt.Sprint(dev, " ", count))
+	// user 2 on item 0: ((2 + 0.5) + (5 + 3)) / 2
+	utils.ExpectNear(t, 5.25, m.Predict(2, 0), 1e-9)
+
+	w := NewSlopeOne(true)
+	w.Fit(ratings())
+	// (2 * 2.5 + 1 * 8) / 3
+	utils.ExpectNear(t, 13.0/3, w.Predict(2, 0), 1e-9)
+	utils.Expect(t, "0", fmt.Sprint(len(w.Recommend(0, nil, 5))))
+	utils.Expect(t, "0", fmt.Sprint(w.Recommend(2, nil, 5)[0].ProductId))
+}
+
+func TestIncrementalUpdate(t *testing.T) {
+	records := ratings()
+	refit := NewSlopeOne(true)
+	refit.Fit(records)
+
+	incremental := NewSlopeOne(true)
+	for _, r := range records {
+		incremental.AddRating(r.UserId, r.ProductId, r.Value)
+	}
+	utils.Expect(t, deviations(refit), deviations(incremental))
+
+	// replace a rating and add a new one
+	incremental.AddRating(1, 1, 1)
+	incremental.AddRating(1, 2, 4)
+	records[4] = core.NewRating(1, 1, 1, time.Now())
+	records = append(records, core.NewRating(1, 2, 4, time.Now()))
+	refit.Fit(records)
+	utils.Expect(t, deviations(refit), deviations(incremental))
+	utils.ExpectNear(t, refit.Predict(2, 0), incremental.Predict(2, 0), 1e-9)
+
+	// remove a rating, and remove another one by rating it 0
+	utils.Expect(t, "true", incremental.RemoveRating(0, 2))
+	utils.Expect(t, "false", incremental.RemoveRating(0, 2))
+	incremental.AddRating(2, 1, 0)
+	refit.Fit([]*core.Record{records[0], records[1], records[3], records[4], records[6], records[7]})
+	utils.Expect(t, deviations(refit), deviations(incremental))
+}
@@ -42,6 +42,16 @@ func (d *Dictionary) GetNameFromId(id int) string {
 	return d.idToName[id]
 }
 
+// Get the id next to the largest one in the dictionary
+func (d *Dictionary) MaxId() int {
+	return d.maxId
+}
+
+// Get the number of names in the dictionary
+func (d *Dictionary) Size() int {
+	return len(d.nameToId)
+}
+
 func (d *Dictionary) AddName(name string) int {
 	id, ok := d.nameToId[name]
 	if ok {